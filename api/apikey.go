@@ -0,0 +1,181 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"go-api/common"
+	"go-api/model"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// apiKeyPrefixLen is the length of the key prefix kept in plain text for identification.
+const apiKeyPrefixLen = 12
+
+type createApiKeyReq struct {
+	Name      string   `json:"name" binding:"required"`
+	Scopes    []string `json:"scopes"`
+	ExpiresIn int      `json:"expires_in"` // 有效天数，为0时不过期
+}
+
+type apiKeyResp struct {
+	model.ApiKey
+	Scopes []string `json:"scopes"`
+	Key    string   `json:"key,omitempty"` // 明文key，仅在创建及轮换时返回
+}
+
+func newApiKeyResp(k model.ApiKey, key string) apiKeyResp {
+	return apiKeyResp{ApiKey: k, Scopes: k.ScopeList(), Key: key}
+}
+
+// CreateApiKey creates an api key for the current user. The plain key is only returned once.
+func CreateApiKey(ctx *gin.Context) {
+	var req createApiKeyReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		common.Abort(common.ErrBind, err, ctx)
+		return
+	}
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		common.Abort(common.ErrSession, err, ctx)
+		return
+	}
+	// key的授权范围不能超过用户自身的权限
+	se := common.CurrentSession(ctx)
+	for _, scope := range req.Scopes {
+		if !se.HasPermission(scope) {
+			common.Abort(common.ErrPermission, fmt.Errorf("missing permission `%s`", scope), ctx)
+			return
+		}
+	}
+
+	key, hash, err := common.GenerateApiKey()
+	if err != nil {
+		common.Abort(common.ErrEncrypt, err, ctx)
+		return
+	}
+	apiKey := model.ApiKey{
+		UserID:  userID,
+		Name:    req.Name,
+		Prefix:  key[:apiKeyPrefixLen],
+		KeyHash: hash,
+		Scopes:  strings.Join(req.Scopes, ","),
+	}
+	if req.ExpiresIn > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresIn)
+		apiKey.ExpiresAt = &expiresAt
+	}
+	if err = common.GetDB(ctx).Create(&apiKey).Error; err != nil {
+		common.Abort(common.ErrDatabase, err, ctx)
+		return
+	}
+
+	common.SuccessReturn(newApiKeyResp(apiKey, key), ctx)
+}
+
+// apiKeyPage lists the sort and filter fields of ListApiKeys.
+var apiKeyPage = common.PageOptions{
+	Sorts: map[string]string{
+		"id":           "id",
+		"name":         "name",
+		"created_at":   "created_at",
+		"last_used_at": "last_used_at",
+		"expires_at":   "expires_at",
+	},
+	Filters: map[string]string{
+		"name":   "name",
+		"prefix": "prefix",
+	},
+	DefaultSort: "id",
+	Cursor:      "id",
+}
+
+// ListApiKeys lists the api keys of the current user, with their last used time. It supports
+// page or cursor pagination, sorting and filtering as described by apiKeyPage.
+func ListApiKeys(ctx *gin.Context) error {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return common.NewError(common.ErrSession, err)
+	}
+	var keys []model.ApiKey
+	page, err := common.Paginate(ctx, common.GetDB(ctx).Where("user_id = ?", userID), apiKeyPage, &keys)
+	if err != nil {
+		return err
+	}
+	res := make([]apiKeyResp, 0, len(keys))
+	for _, k := range keys {
+		res = append(res, newApiKeyResp(k, ""))
+	}
+	page.Result = res
+
+	common.SuccessReturn(page, ctx)
+	return nil
+}
+
+// RotateApiKey replaces the secret of an api key, the old secret stops working immediately.
+func RotateApiKey(ctx *gin.Context) {
+	apiKey, ok := findApiKey(ctx)
+	if !ok {
+		return
+	}
+	key, hash, err := common.GenerateApiKey()
+	if err != nil {
+		common.Abort(common.ErrEncrypt, err, ctx)
+		return
+	}
+	apiKey.Prefix = key[:apiKeyPrefixLen]
+	apiKey.KeyHash = hash
+	apiKey.LastUsedAt = nil
+	if err = common.GetDB(ctx).Select("prefix", "key_hash", "last_used_at").Updates(&apiKey).Error; err != nil {
+		common.Abort(common.ErrDatabase, err, ctx)
+		return
+	}
+
+	common.SuccessReturn(newApiKeyResp(apiKey, key), ctx)
+}
+
+// RevokeApiKey revokes an api key of the current user.
+func RevokeApiKey(ctx *gin.Context) {
+	apiKey, ok := findApiKey(ctx)
+	if !ok {
+		return
+	}
+	now := time.Now()
+	apiKey.RevokedAt = &now
+	if err := common.GetDB(ctx).Select("revoked_at").Updates(&apiKey).Error; err != nil {
+		common.Abort(common.ErrDatabase, err, ctx)
+		return
+	}
+
+	common.SuccessReturn(newApiKeyResp(apiKey, ""), ctx)
+}
+
+// findApiKey loads the active api key given in the `id` param and owned by the current user.
+func findApiKey(ctx *gin.Context) (apiKey model.ApiKey, ok bool) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		common.Abort(common.ErrSession, err, ctx)
+		return
+	}
+	err = common.GetDB(ctx).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", ctx.Param("id"), userID).
+		First(&apiKey).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		common.Abort(common.ErrRecordNotFound, nil, ctx)
+		return
+	} else if err != nil {
+		common.Abort(common.ErrDatabase, err, ctx)
+		return
+	}
+	return apiKey, true
+}
+
+// currentUserID returns the id of the authenticated user.
+func currentUserID(ctx *gin.Context) (uint, error) {
+	id, err := strconv.ParseUint(ctx.GetString("userid"), 10, 64)
+	return uint(id), err
+}
@@ -0,0 +1,317 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"go-api/common"
+	"go-api/model"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// defaultAppid is used when the client does not send an `appid` header.
+const defaultAppid = "web"
+
+type loginReq struct {
+	UserName string `json:"user_name" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	Device   string `json:"device"` // 登录设备名称，用于session列表展示
+}
+
+type loginResp struct {
+	SessionID    string `json:"session_id,omitempty"`
+	Token        string `json:"token,omitempty"`
+	UserID       string `json:"user_id"`
+	UserName     string `json:"user_name"`
+	State        string `json:"state,omitempty"`         // mfa_pending 时需提交两步验证码
+	PendingToken string `json:"pending_token,omitempty"` // 提交两步验证码时使用
+}
+
+// Login verifies the credentials against the users table and finishes the login,
+// or waits for the second factor when the user has enabled two-factor authentication.
+func Login(ctx *gin.Context) {
+	var req loginReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		common.Abort(common.ErrBind, err, ctx)
+		return
+	}
+
+	user, ok := authenticate(ctx, req)
+	if !ok {
+		return
+	}
+	if user.Disabled {
+		common.Abort(common.ErrUserStatus, nil, ctx)
+		return
+	}
+
+	se := newLoginSession(ctx, &user, req.Device)
+	// 已启用两步验证时，先保存等待验证的session，验证码通过后才完成登录
+	if user.TotpEnabled {
+		// 尝试次数用完时不再创建等待验证的session
+		if locked, err := common.MfaLocked(se.UserID); err != nil {
+			common.Abort(common.ErrSession, err, ctx)
+			return
+		} else if locked {
+			common.Abort(common.ErrRateLimit, errMfaLocked, ctx)
+			return
+		}
+		if err := se.SessionPending(); err != nil {
+			common.Abort(common.ErrSession, err, ctx)
+			return
+		}
+		common.SuccessReturn(loginResp{
+			UserID:       se.UserID,
+			UserName:     se.UserName,
+			State:        se.State,
+			PendingToken: se.SessionID,
+		}, ctx)
+		return
+	}
+
+	se.State = passwordState(&user)
+	finishLogin(ctx, se)
+}
+
+// authenticate verifies the credentials against the local users table, or against the
+// directory for users provisioned from it and unknown users when directory login is enabled.
+func authenticate(ctx *gin.Context, req loginReq) (user model.User, ok bool) {
+	db := common.GetDB(ctx)
+	err := db.Preload("Roles.Permissions").Where("user_name = ?", req.UserName).First(&user).Error
+	notFound := errors.Is(err, gorm.ErrRecordNotFound)
+	if err != nil && !notFound {
+		common.Abort(common.ErrDatabase, err, ctx)
+		return
+	}
+
+	if common.LdapEnabled() && (notFound || user.Source == model.UserSourceLdap) {
+		dirUser, code, err := common.LdapAuthenticate(ctx, req.UserName, req.Password)
+		if err != nil {
+			common.Abort(code, err, ctx)
+			return
+		}
+		if user, err = provisionLdapUser(db, user, dirUser); err != nil {
+			common.Abort(common.ErrDatabase, err, ctx)
+			return
+		}
+		return user, true
+	}
+
+	// 用户不存在时Password为空，同样进行一次哈希比较
+	if !common.ComparePassword(user.Password, req.Password) {
+		common.Abort(common.ErrUserIncorrect, nil, ctx)
+		return
+	}
+	return user, true
+}
+
+// provisionLdapUser creates the user on the first directory login, and syncs the roles
+// mapped from the directory groups on every login.
+func provisionLdapUser(db *gorm.DB, user model.User, dirUser *common.DirectoryUser) (model.User, error) {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if user.ID == 0 {
+			user = model.User{
+				UserName: dirUser.UserName,
+				Source:   model.UserSourceLdap,
+			}
+			if err := tx.Create(&user).Error; err != nil {
+				return err
+			}
+			common.LogInfoCtx(tx.Statement.Context, "provisioned user from the directory", logrus.Fields{"user_name": user.UserName, "dn": dirUser.DN})
+		}
+		var roles []model.Role
+		if names := common.LdapRoles(dirUser.Groups); len(names) > 0 {
+			if err := tx.Where("name IN ?", names).Find(&roles).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&user).Association("Roles").Replace(roles)
+	})
+	if err != nil {
+		return user, err
+	}
+	err = db.Preload("Roles.Permissions").First(&user, user.ID).Error
+	return user, err
+}
+
+// newLoginSession builds the session of a user whose credentials have been verified.
+func newLoginSession(ctx *gin.Context, user *model.User, device string) *common.Session {
+	appid := ctx.GetHeader("appid")
+	if appid == "" {
+		appid = defaultAppid
+	}
+	return &common.Session{
+		UserID:      strconv.FormatUint(uint64(user.ID), 10),
+		UserName:    user.UserName,
+		Lock:        user.Lock,
+		Appid:       appid,
+		Roles:       user.RoleNames(),
+		Permissions: user.PermissionCodes(),
+		Device:      device,
+		IP:          ctx.ClientIP(),
+		UserAgent:   ctx.Request.UserAgent(),
+		Lang:        user.Lang,
+	}
+}
+
+// finishLogin registers the session and/or issues a json web token depending on the
+// configured auth mode, and returns them to the client.
+func finishLogin(ctx *gin.Context, se *common.Session) {
+	var err error
+	resp := loginResp{
+		UserID:   se.UserID,
+		UserName: se.UserName,
+	}
+	if common.AuthSessionEnabled() {
+		if se.SessionID, err = se.CreateSessionID(); err != nil {
+			common.Abort(common.ErrSession, err, ctx)
+			return
+		}
+		if err = se.SessionRegister(ctx); err != nil {
+			common.Abort(common.ErrSession, err, ctx)
+			return
+		}
+		ctx.Header("Session", se.SessionID)
+		resp.SessionID = se.SessionID
+	}
+	if common.AuthJwtEnabled() {
+		if resp.Token, err = se.CreateToken(); err != nil {
+			common.Abort(common.ErrTokenSign, err, ctx)
+			return
+		}
+	}
+	// 密码已过期时返回ErrPasswordExpired，通过响应头返回只能修改密码的session及token
+	if se.State == common.SessionStatePasswordExpired {
+		if resp.Token != "" {
+			ctx.Header("X-Auth-Token", resp.Token)
+		}
+		common.Abort(common.ErrPasswordExpired, nil, ctx)
+		return
+	}
+
+	common.SuccessReturn(resp, ctx)
+}
+
+// passwordState returns the state of a new session according to the password age.
+// Passwords of directory users are managed by the directory.
+func passwordState(user *model.User) string {
+	if user.Source == model.UserSourceLocal && common.PasswordExpired(user.PasswordChangedAt()) {
+		return common.SessionStatePasswordExpired
+	}
+	return common.SessionStateActive
+}
+
+// Logout removes the session given in the `Session` header.
+// Json web tokens are stateless, so a client holding only a token simply discards it.
+func Logout(ctx *gin.Context) {
+	sessionID := ctx.GetHeader("Session")
+	if sessionID == "" || !common.AuthSessionEnabled() {
+		if common.AuthJwtEnabled() {
+			common.SuccessReturn(nil, ctx)
+			return
+		}
+		common.Abort(common.ErrMissingAuthorization, nil, ctx)
+		return
+	}
+	se := common.Session{
+		SessionID: sessionID,
+	}
+	if err := se.GetSessionUserID(); err != nil {
+		common.Abort(common.ErrSession, err, ctx)
+		return
+	}
+	if err := se.DeleteSession(ctx); err != nil {
+		common.Abort(common.ErrSession, err, ctx)
+		return
+	}
+
+	common.SuccessReturn(nil, ctx)
+}
+
+// RefreshSession replaces the current session with a new session id and issues a new token.
+func RefreshSession(ctx *gin.Context) {
+	se := common.CurrentSession(ctx)
+	resp := loginResp{
+		UserID:   se.UserID,
+		UserName: se.UserName,
+	}
+	// 通过session认证的请求才需要更换session_id
+	if se.SessionID != "" {
+		newSe, err := se.RefreshSession(ctx)
+		if err != nil {
+			common.Abort(common.ErrSession, err, ctx)
+			return
+		}
+		se = newSe
+		ctx.Header("Session", se.SessionID)
+		resp.SessionID = se.SessionID
+	}
+	if common.AuthJwtEnabled() {
+		var err error
+		if resp.Token, err = se.CreateToken(); err != nil {
+			common.Abort(common.ErrTokenSign, err, ctx)
+			return
+		}
+	}
+
+	common.SuccessReturn(resp, ctx)
+}
+
+type languageReq struct {
+	Lang string `json:"lang"` // 为空时清除偏好，使用Accept-Language
+}
+
+// UpdateLanguage saves the language of the error messages for the current user. Clients
+// authenticated by a json web token receive a new token carrying the preference.
+func UpdateLanguage(ctx *gin.Context) {
+	var req languageReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		common.Abort(common.ErrBind, err, ctx)
+		return
+	}
+	req.Lang = strings.ToLower(req.Lang)
+	if req.Lang != "" && !supportedLanguage(req.Lang) {
+		common.Abort(common.ErrValidation, fmt.Errorf("unsupported language `%s`, supported: %s",
+			req.Lang, strings.Join(common.ErrnoLocales(), ", ")), ctx)
+		return
+	}
+	se := common.CurrentSession(ctx)
+	if err := common.GetDB(ctx).Model(&model.User{}).Where("id = ?", se.UserID).Update("lang", req.Lang).Error; err != nil {
+		common.Abort(common.ErrDatabase, err, ctx)
+		return
+	}
+	se.Lang = req.Lang
+
+	resp := loginResp{
+		UserID:   se.UserID,
+		UserName: se.UserName,
+	}
+	if se.SessionID != "" {
+		if err := se.SetSession(); err != nil {
+			common.Abort(common.ErrSession, err, ctx)
+			return
+		}
+	} else if common.AuthJwtEnabled() {
+		var err error
+		if resp.Token, err = se.CreateToken(); err != nil {
+			common.Abort(common.ErrTokenSign, err, ctx)
+			return
+		}
+	}
+
+	common.SuccessReturn(resp, ctx)
+}
+
+// supportedLanguage reports whether the catalog has messages in the language.
+func supportedLanguage(lang string) bool {
+	for _, l := range common.ErrnoLocales() {
+		if l == lang || l == strings.SplitN(lang, "-", 2)[0] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,235 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"go-api/common"
+	"go-api/model"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// recordDriver 记录执行的语句，查询按表名返回预设的结果
+type recordDriver struct {
+	mu     sync.Mutex
+	execs  []recordExec
+	tables map[string]recordRows // 表名对应的查询结果
+	lastID int64
+}
+
+type recordExec struct {
+	query string
+	args  []driver.Value
+}
+
+type recordRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (d *recordDriver) Open(string) (driver.Conn, error) {
+	return &recordConn{d: d}, nil
+}
+
+// exec 返回包含table的语句
+func (d *recordDriver) exec(table string) []recordExec {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var res []recordExec
+	for _, e := range d.execs {
+		if strings.Contains(e.query, "`"+table+"`") {
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+type recordConn struct {
+	d *recordDriver
+}
+
+func (c *recordConn) Prepare(string) (driver.Stmt, error) {
+	return nil, driver.ErrSkip
+}
+
+func (c *recordConn) Close() error {
+	return nil
+}
+
+func (c *recordConn) Begin() (driver.Tx, error) {
+	return c, nil
+}
+
+func (c *recordConn) Commit() error {
+	return nil
+}
+
+func (c *recordConn) Rollback() error {
+	return nil
+}
+
+func (c *recordConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	e := recordExec{query: query}
+	for _, arg := range args {
+		e.args = append(e.args, arg.Value)
+	}
+	c.d.execs = append(c.d.execs, e)
+	c.d.lastID++
+	return recordResult(c.d.lastID), nil
+}
+
+func (c *recordConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	from := strings.SplitN(query, "FROM `", 2)
+	if len(from) == 2 {
+		if rows, ok := c.d.tables[strings.SplitN(from[1], "`", 2)[0]]; ok {
+			return &recordCursor{rows: rows}, nil
+		}
+	}
+	return &recordCursor{}, nil
+}
+
+type recordResult int64
+
+func (r recordResult) LastInsertId() (int64, error) {
+	return int64(r), nil
+}
+
+func (r recordResult) RowsAffected() (int64, error) {
+	return 1, nil
+}
+
+type recordCursor struct {
+	rows recordRows
+	next int
+}
+
+func (r *recordCursor) Columns() []string {
+	return r.rows.columns
+}
+
+func (r *recordCursor) Close() error {
+	return nil
+}
+
+func (r *recordCursor) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows.values) {
+		return io.EOF
+	}
+	copy(dest, r.rows.values[r.next])
+	r.next++
+	return nil
+}
+
+var registerDriver sync.Once
+
+// openRecordDB 使用recordDriver打开mysql方言的gorm连接
+func openRecordDB(t *testing.T, d *recordDriver) *gorm.DB {
+	t.Helper()
+	registerDriver.Do(func() {
+		sql.Register("record", &recordProxy{})
+	})
+	recordProxyDriver = d
+	sqlDB, err := sql.Open("record", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// recordProxy 转发至当前测试的recordDriver，sql.Register只能注册一次
+type recordProxy struct{}
+
+var recordProxyDriver *recordDriver
+
+func (recordProxy) Open(name string) (driver.Conn, error) {
+	return recordProxyDriver.Open(name)
+}
+
+func TestProvisionLdapUser(t *testing.T) {
+	common.CONFIG = &common.App{WindowsAd: common.WindowsAdConfig{
+		GroupRoles: map[string]string{"DBA": "dba"},
+	}}
+	d := &recordDriver{
+		lastID: 41,
+		tables: map[string]recordRows{
+			"roles": {
+				columns: []string{"id", "name"},
+				values:  [][]driver.Value{{int64(2), "dba"}},
+			},
+			"users": {
+				columns: []string{"id", "user_name", "source"},
+				values:  [][]driver.Value{{int64(42), "miku", "ldap"}},
+			},
+			"user_role": {
+				columns: []string{"user_id", "role_id"},
+				values:  [][]driver.Value{{int64(42), int64(2)}},
+			},
+		},
+	}
+	db := openRecordDB(t, d)
+
+	user, err := provisionLdapUser(db, model.User{}, &common.DirectoryUser{
+		DN:       "CN=Miku,OU=Users,DC=miku,DC=local",
+		UserName: "miku",
+		Groups:   []string{"CN=DBA,OU=Groups,DC=miku,DC=local"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 首次登录时创建来源为ldap的用户
+	var inserts []recordExec
+	for _, e := range d.exec("users") {
+		if strings.HasPrefix(e.query, "INSERT INTO `users`") {
+			inserts = append(inserts, e)
+		}
+	}
+	if len(inserts) != 1 {
+		t.Fatalf("users statements = %+v, want one insert", d.exec("users"))
+	}
+	if !containsValue(inserts[0].args, "miku") || !containsValue(inserts[0].args, "ldap") {
+		t.Fatalf("user insert args = %v, want user name and source", inserts[0].args)
+	}
+	// 目录组映射的角色关联至新用户
+	var linked bool
+	for _, e := range d.exec("user_role") {
+		if strings.HasPrefix(e.query, "INSERT INTO `user_role`") && containsValue(e.args, int64(42)) && containsValue(e.args, int64(2)) {
+			linked = true
+		}
+	}
+	if !linked {
+		t.Fatalf("user_role statements = %+v, want the dba role linked to the user", d.exec("user_role"))
+	}
+	if user.ID != 42 || len(user.Roles) != 1 || user.Roles[0].Name != "dba" {
+		t.Fatalf("provisioned user = %+v", user)
+	}
+}
+
+func containsValue(values []driver.Value, want driver.Value) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+		if n, ok := v.(uint64); ok && int64(n) == want {
+			return true
+		}
+	}
+	return false
+}
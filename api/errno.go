@@ -0,0 +1,147 @@
+package api
+
+import (
+	"errors"
+	"go-api/common"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type errnoReq struct {
+	Service    string            `json:"service"`
+	ErrType    string            `json:"type"`
+	ErrorCode  int               `json:"error_code"`
+	HttpStatus int               `json:"http_status"`
+	MessageEn  string            `json:"message_en"`
+	MessageZh  string            `json:"message_zh"`
+	Messages   map[string]string `json:"messages"` // 其他语言的错误信息
+}
+
+func (r errnoReq) toError() common.Error {
+	return common.Error{
+		Service:    r.Service,
+		ErrType:    r.ErrType,
+		ErrorCode:  r.ErrorCode,
+		HttpStatus: r.HttpStatus,
+		MessageEn:  r.MessageEn,
+		MessageZh:  r.MessageZh,
+		Messages:   r.Messages,
+	}
+}
+
+// newRow returns the row created for the request. gorm hands a nil map to the driver
+// instead of serializing it, so the messages default to an empty object.
+func (r errnoReq) newRow() common.Error {
+	e := r.toError()
+	if e.Messages == nil {
+		e.Messages = map[string]string{}
+	}
+	return e
+}
+
+// validate checks the http status before it is stored, Abort writes it as the response
+// status of every request failing with the error.
+func (r errnoReq) validate() error {
+	if r.HttpStatus != 0 && (r.HttpStatus < 100 || r.HttpStatus > 599) {
+		return common.Errorf(common.ErrValidation, "`http_status` must be 0 or between 100 and 599")
+	}
+	return nil
+}
+
+// ListErrors lists the error messages currently in use, builtin entries merged with the database.
+func ListErrors(ctx *gin.Context) error {
+	messages := common.ErrorMessages()
+	res := make([]common.Error, 0, len(messages))
+	for _, v := range messages {
+		res = append(res, v)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].ErrorCode < res[j].ErrorCode
+	})
+
+	common.SuccessReturn(res, ctx)
+	return nil
+}
+
+// CreateError stores a new error message, or a database override of a builtin one.
+func CreateError(ctx *gin.Context) error {
+	var req errnoReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		return common.NewError(common.ErrBind, err)
+	}
+	if req.ErrType == "" || req.MessageEn == "" || req.MessageZh == "" {
+		return common.Errorf(common.ErrValidation, "`type`, `message_en` and `message_zh` are required")
+	}
+	if err := req.validate(); err != nil {
+		return err
+	}
+	db := common.GetDB(ctx)
+	var count int64
+	if err := db.Table(common.ErrnoTable).Where("err_type = ?", req.ErrType).Count(&count).Error; err != nil {
+		return common.NewError(common.ErrDatabase, err)
+	}
+	if count > 0 {
+		return common.NewError(common.ErrDuplicate, nil).With("type", req.ErrType)
+	}
+
+	e := req.newRow()
+	if err := db.Table(common.ErrnoTable).Create(&e).Error; err != nil {
+		return common.NewError(common.ErrDatabase, err)
+	}
+	return reloadErrors(ctx, e.ErrType)
+}
+
+// UpdateError updates the error message given in the `type` param. Builtin messages
+// without a database row get one, so the change survives restarts.
+func UpdateError(ctx *gin.Context) error {
+	var req errnoReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		return common.NewError(common.ErrBind, err)
+	}
+	req.ErrType = ctx.Param("type")
+	if err := req.validate(); err != nil {
+		return err
+	}
+
+	db := common.GetDB(ctx)
+	var e common.Error
+	err := db.Table(common.ErrnoTable).Where("err_type = ?", req.ErrType).First(&e).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if _, ok := common.ErrorMessages()[req.ErrType]; !ok {
+			return common.NewError(common.ErrRecordNotFound, nil).With("type", req.ErrType)
+		}
+		e = req.newRow()
+		err = db.Table(common.ErrnoTable).Create(&e).Error
+	case err == nil:
+		// 只更新请求中给出的字段
+		err = db.Table(common.ErrnoTable).Where("id = ?", e.ID).Updates(req.toError()).Error
+	}
+	if err != nil {
+		return common.NewError(common.ErrDatabase, err)
+	}
+	return reloadErrors(ctx, req.ErrType)
+}
+
+// ReloadErrors reloads the error messages from the database on all instances.
+func ReloadErrors(ctx *gin.Context) error {
+	return reloadErrors(ctx, "")
+}
+
+// reloadErrors swaps the in-memory messages, notifies the other instances, and returns
+// the entry of errType when given.
+func reloadErrors(ctx *gin.Context, errType string) error {
+	if err := common.ReloadErrnoMessages(); err != nil {
+		return common.NewError(common.ErrDatabase, err)
+	}
+	common.PublishErrnoReload(ctx)
+
+	if errType == "" {
+		common.SuccessReturn(nil, ctx)
+		return nil
+	}
+	common.SuccessReturn(common.ErrorMessages()[errType], ctx)
+	return nil
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"go-api/common"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestErrorHttpStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	common.CONFIG = &common.App{}
+	d := &recordDriver{}
+	db := openRecordDB(t, d)
+	g := gin.New()
+	g.Use(func(c *gin.Context) {
+		c.Set("DB", db)
+	}, common.RenderErrors)
+	g.POST("/v1/admin/errors", common.Handle(CreateError))
+	g.PUT("/v1/admin/errors/:type", common.Handle(UpdateError))
+
+	for _, status := range []int{-1, 42, 99, 600, 1000} {
+		for _, route := range []struct{ method, path string }{
+			{http.MethodPost, "/v1/admin/errors"},
+			{http.MethodPut, "/v1/admin/errors/ErrTest"},
+		} {
+			body, _ := json.Marshal(map[string]interface{}{
+				"type": "ErrTest", "http_status": status, "message_en": "test", "message_zh": "测试",
+			})
+			w := httptest.NewRecorder()
+			g.ServeHTTP(w, httptest.NewRequest(route.method, route.path, strings.NewReader(string(body))))
+			var res common.Req
+			if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+				t.Fatalf("body %s: %v", w.Body, err)
+			}
+			if w.Code != http.StatusBadRequest || res.Msg.ErrType != common.ErrValidation {
+				t.Fatalf("%s http_status %d = %d %s, want %s", route.method, status, w.Code, w.Body, common.ErrValidation)
+			}
+		}
+	}
+	// 校验失败时不写入数据库
+	if len(d.execs) != 0 {
+		t.Fatalf("statements = %+v, want none", d.execs)
+	}
+}
+
+func TestUpdateError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	common.CONFIG = &common.App{}
+	tests := []struct {
+		name    string
+		errType string
+		rows    [][]driver.Value // miku_errors中已有的记录
+		status  int
+		stmt    string
+		message string // 重新加载后的英文信息
+	}{
+		// 内置错误信息没有记录时新增，重启后仍然生效
+		{"builtin", common.ErrBind, nil, http.StatusOK, "INSERT INTO `miku_errors`", "Error occurred while binding the request body to the struct."},
+		{"override", common.ErrBind, [][]driver.Value{{int64(7), common.ErrBind, "Invalid body."}}, http.StatusOK, "UPDATE `miku_errors`", "Invalid body."},
+		{"undefined", "ErrNotDefined", nil, http.StatusNotFound, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &recordDriver{tables: map[string]recordRows{}}
+			if tt.rows != nil {
+				d.tables[common.ErrnoTable] = recordRows{columns: []string{"id", "err_type", "message_en"}, values: tt.rows}
+			}
+			db := openRecordDB(t, d)
+			common.GetErrnoMessages(db)
+			t.Cleanup(func() {
+				// 恢复为内置错误信息
+				delete(d.tables, common.ErrnoTable)
+				_ = common.ReloadErrnoMessages()
+			})
+			g := gin.New()
+			g.Use(func(c *gin.Context) {
+				c.Set("DB", db)
+			}, common.RenderErrors)
+			g.PUT("/v1/admin/errors/:type", common.Handle(UpdateError))
+
+			w := httptest.NewRecorder()
+			g.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/v1/admin/errors/"+tt.errType,
+				strings.NewReader(`{"http_status": 422, "message_zh": "请求无效"}`)))
+			if w.Code != tt.status {
+				t.Fatalf("status = %d %s, want %d", w.Code, w.Body, tt.status)
+			}
+			if tt.stmt == "" {
+				if len(d.execs) != 0 {
+					t.Fatalf("statements = %+v, want none", d.execs)
+				}
+				return
+			}
+			execs := d.exec(common.ErrnoTable)
+			if len(execs) != 1 || !strings.HasPrefix(execs[0].query, tt.stmt) || !containsValue(execs[0].args, "请求无效") {
+				t.Fatalf("statements = %+v, want %s", execs, tt.stmt)
+			}
+			// 写入后重新加载，返回合并后的错误信息
+			var res struct {
+				Data common.Error `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+				t.Fatal(err)
+			}
+			if res.Data.ErrType != tt.errType || res.Data.MessageEn != tt.message || common.ErrorMessages()[tt.errType].MessageEn != tt.message {
+				t.Fatalf("reloaded = %+v, want message_en %q", res.Data, tt.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"go-api/common"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type logLevelReq struct {
+	Route    string `json:"route"` // 路由，如 /v1/auth/login，只在修改路由的级别时使用
+	Level    string `json:"level" binding:"required"`
+	Duration int    `json:"duration"` // 到期后恢复，单位秒，为0时不恢复
+}
+
+type logLevelResp struct {
+	Level  string              `json:"level"`
+	Routes []common.RouteLevel `json:"routes"`
+}
+
+// GetLogLevel returns the global log level and the route overrides.
+func GetLogLevel(ctx *gin.Context) error {
+	common.SuccessReturn(logLevelResp{
+		Level:  common.LogLevelName(common.LogLevel()),
+		Routes: common.RouteLogLevels(),
+	}, ctx)
+	return nil
+}
+
+// SetLogLevel changes the global log level of this instance, reverting it after
+// `duration` seconds when given.
+func SetLogLevel(ctx *gin.Context) error {
+	level, req, err := bindLogLevel(ctx)
+	if err != nil {
+		return err
+	}
+	common.SetLogLevelFor(level, time.Duration(req.Duration)*time.Second)
+	common.LogWarnCtx(ctx, "log level changed", logrus.Fields{"level": req.Level, "duration": req.Duration})
+	return GetLogLevel(ctx)
+}
+
+// SetRouteLogLevel sets the log level of the requests to a route, which falls back to
+// the global level after `duration` seconds when given.
+func SetRouteLogLevel(ctx *gin.Context) error {
+	level, req, err := bindLogLevel(ctx)
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(req.Route, "/") {
+		return common.Errorf(common.ErrValidation, "`route` must be a route path, such as /v1/auth/login")
+	}
+	common.SetRouteLogLevel(req.Route, level, time.Duration(req.Duration)*time.Second)
+	common.LogWarnCtx(ctx, "route log level changed", logrus.Fields{"route": req.Route, "level": req.Level, "duration": req.Duration})
+	return GetLogLevel(ctx)
+}
+
+// DeleteRouteLogLevel removes the log level of the route given in the `route` query.
+func DeleteRouteLogLevel(ctx *gin.Context) error {
+	route := ctx.Query("route")
+	if !common.DeleteRouteLogLevel(route) {
+		return common.NewError(common.ErrRecordNotFound, nil).With("route", route)
+	}
+	return GetLogLevel(ctx)
+}
+
+// ReopenLogFiles reopens the log files right away, after they have been moved by an
+// external logrotate.
+func ReopenLogFiles(ctx *gin.Context) error {
+	if err := common.LogReopen(); err != nil {
+		return common.NewError(common.ErrUnknown, err)
+	}
+	common.LogInfoCtx(ctx, "log files reopened", nil)
+	common.SuccessReturn(nil, ctx)
+	return nil
+}
+
+func bindLogLevel(ctx *gin.Context) (logrus.Level, logLevelReq, error) {
+	var req logLevelReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		return 0, req, common.NewError(common.ErrBind, err)
+	}
+	if req.Duration < 0 {
+		return 0, req, common.Errorf(common.ErrValidation, "`duration` must not be negative")
+	}
+	level, err := common.ParseLogLevel(req.Level)
+	if err != nil {
+		return 0, req, common.NewError(common.ErrValidation, err)
+	}
+	req.Level = common.LogLevelName(level)
+	return level, req, nil
+}
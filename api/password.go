@@ -0,0 +1,124 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"go-api/common"
+	"go-api/model"
+	"go-api/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type changePasswordReq struct {
+	OldPassword string `json:"old_password" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+type changePasswordResp struct {
+	Token string `json:"token,omitempty"` // 密码过期的session修改密码后重新签发的token
+}
+
+// ChangePassword changes the password of the current user. It is also the only api a
+// restricted session with an expired password can call, and lifts the restriction.
+func ChangePassword(ctx *gin.Context) {
+	var req changePasswordReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		common.Abort(common.ErrBind, err, ctx)
+		return
+	}
+	user, ok := loadCurrentUser(ctx)
+	if !ok {
+		return
+	}
+	if user.Source != model.UserSourceLocal {
+		common.Abort(common.ErrWindowsAdError, errors.New("the password of a directory user is managed by the directory"), ctx)
+		return
+	}
+	if !utils.PasswordCompare(user.Password, req.OldPassword) {
+		common.Abort(common.ErrUserIncorrect, nil, ctx)
+		return
+	}
+	if err := common.CheckPasswordRule(req.NewPassword); err != nil {
+		common.Abort(common.ErrPasswordRule, err, ctx)
+		return
+	}
+
+	db := common.GetDB(ctx)
+	history := common.CONFIG.Password.History
+	// 当前密码也计入最近使用过的密码
+	if history > 0 {
+		var used []model.PasswordHistory
+		if history > 1 {
+			if err := db.Where("user_id = ?", user.ID).Order("id desc").Limit(history - 1).Find(&used).Error; err != nil {
+				common.Abort(common.ErrDatabase, err, ctx)
+				return
+			}
+		}
+		used = append(used, model.PasswordHistory{Hash: user.Password})
+		for _, h := range used {
+			if utils.PasswordCompare(h.Hash, req.NewPassword) {
+				common.Abort(common.ErrPasswordRule, fmt.Errorf("the password must not be one of the last %d passwords", history), ctx)
+				return
+			}
+		}
+	}
+
+	hash, err := common.HashPassword(req.NewPassword)
+	if err != nil {
+		common.Abort(common.ErrEncrypt, err, ctx)
+		return
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&user).Updates(map[string]interface{}{
+			"password":    hash,
+			"password_at": time.Now(),
+		}).Error
+		if err != nil || history <= 1 {
+			return err
+		}
+		// 记录旧密码，只保留策略需要的最近history-1条（当前密码单独比较）
+		if err = tx.Create(&model.PasswordHistory{UserID: user.ID, Hash: user.Password}).Error; err != nil {
+			return err
+		}
+		var keep []uint
+		if err = tx.Model(&model.PasswordHistory{}).Where("user_id = ?", user.ID).
+			Order("id desc").Limit(history-1).Pluck("id", &keep).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ? AND id NOT IN ?", user.ID, keep).Delete(&model.PasswordHistory{}).Error
+	})
+	if err != nil {
+		common.Abort(common.ErrDatabase, err, ctx)
+		return
+	}
+
+	resp, err := liftPasswordExpired(ctx)
+	if err != nil {
+		common.Abort(common.ErrSession, err, ctx)
+		return
+	}
+	common.SuccessReturn(resp, ctx)
+}
+
+// liftPasswordExpired turns a restricted session into a normal one after the password changed.
+func liftPasswordExpired(ctx *gin.Context) (resp changePasswordResp, err error) {
+	se := common.CurrentSession(ctx)
+	if se == nil || se.State != common.SessionStatePasswordExpired {
+		return
+	}
+	se.State = common.SessionStateActive
+	if se.SessionID != "" {
+		if err = se.SetSession(); err != nil {
+			return
+		}
+	}
+	if common.AuthJwtEnabled() {
+		if resp.Token, err = se.CreateToken(); err != nil {
+			return resp, errors.New("failed to sign the new token: " + err.Error())
+		}
+	}
+	return
+}
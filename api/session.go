@@ -0,0 +1,72 @@
+package api
+
+import (
+	"errors"
+	"go-api/common"
+
+	"github.com/gin-gonic/gin"
+)
+
+type revokeResp struct {
+	Count int `json:"count"`
+}
+
+// ListSessions lists the active sessions of the current user.
+func ListSessions(ctx *gin.Context) {
+	var current string
+	if se := common.CurrentSession(ctx); se != nil {
+		current = se.SessionID
+	}
+	sessions, err := common.ListSessions(ctx.GetString("userid"), current)
+	if err != nil {
+		common.Abort(common.ErrSession, err, ctx)
+		return
+	}
+
+	common.SuccessReturn(sessions, ctx)
+}
+
+// RevokeSession revokes one session of the current user by its handle.
+func RevokeSession(ctx *gin.Context) {
+	revokeSessions(ctx, ctx.GetString("userid"), ctx.Param("handle"))
+}
+
+// RevokeAllSessions revokes every session of the current user, including the current one.
+func RevokeAllSessions(ctx *gin.Context) {
+	revokeSessions(ctx, ctx.GetString("userid"), "")
+}
+
+// ListUserSessions lists the active sessions of any user, for administrators.
+func ListUserSessions(ctx *gin.Context) {
+	sessions, err := common.ListSessions(ctx.Param("userid"), "")
+	if err != nil {
+		common.Abort(common.ErrSession, err, ctx)
+		return
+	}
+
+	common.SuccessReturn(sessions, ctx)
+}
+
+// RevokeUserSession force-logouts one session of any user, for administrators.
+func RevokeUserSession(ctx *gin.Context) {
+	revokeSessions(ctx, ctx.Param("userid"), ctx.Param("handle"))
+}
+
+// RevokeUserSessions force-logouts every session of any user, for administrators.
+func RevokeUserSessions(ctx *gin.Context) {
+	revokeSessions(ctx, ctx.Param("userid"), "")
+}
+
+func revokeSessions(ctx *gin.Context, userID, handle string) {
+	count, err := common.RevokeSessions(ctx, userID, handle)
+	if err != nil {
+		common.Abort(common.ErrSession, err, ctx)
+		return
+	}
+	if handle != "" && count == 0 {
+		common.Abort(common.ErrRecordNotFound, errors.New("no active session matches the handle"), ctx)
+		return
+	}
+
+	common.SuccessReturn(revokeResp{Count: count}, ctx)
+}
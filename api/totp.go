@@ -0,0 +1,291 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go-api/common"
+	"go-api/model"
+	"go-api/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	// totpIssuer is shown by the authenticator apps next to the account name.
+	totpIssuer = "Go-Web-Api"
+	// totpSkew is the number of time steps accepted before and after the current one.
+	totpSkew = 1
+	// recoveryCodeCount is the number of one-time recovery codes generated on activation.
+	recoveryCodeCount = 10
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+var errMfaLocked = errors.New("too many verification attempts, try again later")
+
+type totpCodeReq struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type totpVerifyReq struct {
+	PendingToken string `json:"pending_token" binding:"required"`
+	totpCodeReq
+}
+
+type totpEnrollResp struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"` // otpauth:// 地址，用于生成二维码
+}
+
+type totpActivateResp struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// EnrollTotp generates a new TOTP secret for the current user. Two-factor authentication
+// is only enabled after a code generated from this secret is posted to ActivateTotp.
+func EnrollTotp(ctx *gin.Context) {
+	user, ok := loadCurrentUser(ctx)
+	if !ok {
+		return
+	}
+	if user.TotpEnabled {
+		common.Abort(common.ErrDuplicate, errors.New("two-factor authentication is already enabled"), ctx)
+		return
+	}
+	secret, err := utils.TOTPSecret()
+	if err != nil {
+		common.Abort(common.ErrEncrypt, err, ctx)
+		return
+	}
+	if err = common.GetDB(ctx).Model(&user).Update("totp_secret", secret).Error; err != nil {
+		common.Abort(common.ErrDatabase, err, ctx)
+		return
+	}
+
+	common.SuccessReturn(totpEnrollResp{
+		Secret: secret,
+		URI:    utils.TOTPURI(totpIssuer, user.UserName, secret),
+	}, ctx)
+}
+
+// ActivateTotp enables two-factor authentication once the user proves the enrolled secret
+// works, and returns the one-time recovery codes.
+func ActivateTotp(ctx *gin.Context) {
+	var req totpCodeReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		common.Abort(common.ErrBind, err, ctx)
+		return
+	}
+	user, ok := loadCurrentUser(ctx)
+	if !ok {
+		return
+	}
+	if user.TotpEnabled {
+		common.Abort(common.ErrDuplicate, errors.New("two-factor authentication is already enabled"), ctx)
+		return
+	}
+	if user.TotpSecret == "" {
+		common.Abort(common.ErrGoogleVerify, errors.New("enroll a secret before activating two-factor authentication"), ctx)
+		return
+	}
+	step, ok := utils.TOTPVerify(user.TotpSecret, req.Code, time.Now(), totpSkew)
+	if !ok {
+		common.Abort(common.ErrGoogleVerify, nil, ctx)
+		return
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		common.Abort(common.ErrEncrypt, err, ctx)
+		return
+	}
+	err = common.GetDB(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&user).Updates(map[string]interface{}{
+			"totp_enabled":   true,
+			"totp_last_step": step,
+		}).Error
+		if err != nil {
+			return err
+		}
+		if err = tx.Where("user_id = ?", user.ID).Delete(&model.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		rows := make([]model.RecoveryCode, 0, len(hashes))
+		for _, hash := range hashes {
+			rows = append(rows, model.RecoveryCode{UserID: user.ID, CodeHash: hash})
+		}
+		return tx.Create(&rows).Error
+	})
+	if err != nil {
+		common.Abort(common.ErrDatabase, err, ctx)
+		return
+	}
+
+	common.SuccessReturn(totpActivateResp{RecoveryCodes: codes}, ctx)
+}
+
+// DisableTotp turns off two-factor authentication, a valid code or recovery code is required.
+func DisableTotp(ctx *gin.Context) {
+	var req totpCodeReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		common.Abort(common.ErrBind, err, ctx)
+		return
+	}
+	user, ok := loadCurrentUser(ctx)
+	if !ok {
+		return
+	}
+	if !user.TotpEnabled {
+		common.Abort(common.ErrGoogleVerify, errors.New("two-factor authentication is not enabled"), ctx)
+		return
+	}
+	db := common.GetDB(ctx)
+	if !checkSecondFactor(ctx, db, &user, req) {
+		return
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&user).Updates(map[string]interface{}{
+			"totp_enabled": false,
+			"totp_secret":  "",
+		}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", user.ID).Delete(&model.RecoveryCode{}).Error
+	})
+	if err != nil {
+		common.Abort(common.ErrDatabase, err, ctx)
+		return
+	}
+
+	common.SuccessReturn(nil, ctx)
+}
+
+// VerifyTotp completes a login waiting for the second factor.
+func VerifyTotp(ctx *gin.Context) {
+	var req totpVerifyReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		common.Abort(common.ErrBind, err, ctx)
+		return
+	}
+	se, err := common.GetPendingSession(req.PendingToken)
+	if err != nil {
+		common.Abort(common.ErrSession, err, ctx)
+		return
+	}
+	db := common.GetDB(ctx)
+	var user model.User
+	if err = db.Where("id = ?", se.UserID).First(&user).Error; err != nil {
+		common.Abort(common.ErrDatabase, err, ctx)
+		return
+	}
+	if !checkSecondFactor(ctx, db, &user, req.totpCodeReq) {
+		// 用完尝试次数后等待中的session不再可用
+		if locked, _ := common.MfaLocked(se.UserID); locked {
+			_ = se.DeletePending()
+		}
+		return
+	}
+
+	if err = se.DeletePending(); err != nil {
+		common.Abort(common.ErrSession, err, ctx)
+		return
+	}
+	se.SessionID = ""
+	se.State = passwordState(&user)
+	finishLogin(ctx, se)
+}
+
+// checkSecondFactor verifies the second factor and aborts the request when it fails. Attempts
+// are counted per user before verifying, so neither a new login nor parallel requests get more.
+func checkSecondFactor(ctx *gin.Context, db *gorm.DB, user *model.User, req totpCodeReq) bool {
+	userID := strconv.FormatUint(uint64(user.ID), 10)
+	remaining, ok, err := common.MfaAttempt(userID)
+	if err != nil {
+		common.Abort(common.ErrSession, err, ctx)
+		return false
+	} else if !ok {
+		common.Abort(common.ErrRateLimit, errMfaLocked, ctx)
+		return false
+	}
+	if ok, err = verifySecondFactor(db, user, req); err != nil {
+		common.Abort(common.ErrDatabase, err, ctx)
+		return false
+	} else if !ok {
+		common.Abort(common.ErrGoogleVerify, fmt.Errorf("invalid verification code, %d attempts left", remaining), ctx)
+		return false
+	}
+	if err = common.MfaReset(userID); err != nil {
+		common.Abort(common.ErrSession, err, ctx)
+		return false
+	}
+	return true
+}
+
+// verifySecondFactor checks a TOTP code, or consumes a recovery code when no TOTP code is given.
+func verifySecondFactor(db *gorm.DB, user *model.User, req totpCodeReq) (bool, error) {
+	if req.Code != "" {
+		step, ok := utils.TOTPVerify(user.TotpSecret, req.Code, time.Now(), totpSkew)
+		// 同一时间步的验证码只能使用一次
+		if !ok || step <= user.TotpLastStep {
+			return false, nil
+		}
+		res := db.Model(user).Where("totp_last_step < ?", step).Update("totp_last_step", step)
+		return res.RowsAffected == 1, res.Error
+	}
+	if req.RecoveryCode != "" {
+		res := db.Model(&model.RecoveryCode{}).
+			Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, hashRecoveryCode(req.RecoveryCode)).
+			Update("used_at", time.Now())
+		return res.RowsAffected == 1, res.Error
+	}
+	return false, nil
+}
+
+// generateRecoveryCodes returns the recovery codes in the `xxxxx-xxxxx` format and their hashes.
+func generateRecoveryCodes() (codes, hashes []string, err error) {
+	for i := 0; i < recoveryCodeCount; i++ {
+		random := make([]byte, 7)
+		if _, err = rand.Read(random); err != nil {
+			return
+		}
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(random))[:10]
+		codes = append(codes, code[:5]+"-"+code[5:])
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	return
+}
+
+// hashRecoveryCode hashes a recovery code, ignoring case, dashes and spaces.
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// loadCurrentUser loads the authenticated user from the database.
+func loadCurrentUser(ctx *gin.Context) (user model.User, ok bool) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		common.Abort(common.ErrSession, err, ctx)
+		return
+	}
+	err = common.GetDB(ctx).First(&user, userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		common.Abort(common.ErrRecordNotFound, nil, ctx)
+		return
+	} else if err != nil {
+		common.Abort(common.ErrDatabase, err, ctx)
+		return
+	}
+	return user, true
+}
@@ -0,0 +1,267 @@
+// Command errgen keeps the Err* constants of the common package and the error catalog in sync.
+//
+// The catalog common/errno.yaml is the single source: `generate` writes the Go constants
+// and the SQL seed of miku_errors, `check` fails when a constant has no catalog entry or
+// a catalog entry has no constant.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// entry is an error of the catalog, the fields match common.Error.
+type entry struct {
+	Service    string            `yaml:"service"`
+	ErrType    string            `yaml:"type"`
+	ErrorCode  int               `yaml:"error_code"`
+	HttpStatus int               `yaml:"http_status"`
+	MessageEn  string            `yaml:"message_en"`
+	MessageZh  string            `yaml:"message_zh"`
+	Messages   map[string]string `yaml:"messages"`
+}
+
+func main() {
+	catalogFlag := &cli.StringFlag{
+		Name:  "catalog",
+		Usage: "the error catalog file.",
+		Value: "errno.yaml",
+	}
+	app := &cli.App{
+		Name:  "errgen",
+		Usage: "Keep the Err* constants and the error catalog in sync",
+		Commands: []*cli.Command{
+			{
+				Name:  "generate",
+				Usage: "generate the Go constants and the SQL seed from the catalog",
+				Flags: []cli.Flag{
+					catalogFlag,
+					&cli.StringFlag{Name: "pkg", Usage: "the package of the Go file.", Value: "common"},
+					&cli.StringFlag{Name: "out", Usage: "the generated Go file.", Value: "errno.gen.go"},
+					&cli.StringFlag{Name: "sql", Usage: "the generated SQL seed, skipped when empty."},
+					&cli.StringFlag{Name: "service", Usage: "the service of entries without one.", Value: "miku"},
+				},
+				Action: generate,
+			},
+			{
+				Name:  "check",
+				Usage: "fail when the constants of a package and the catalog disagree",
+				Flags: []cli.Flag{
+					catalogFlag,
+					&cli.StringFlag{Name: "dir", Usage: "the package directory.", Value: "."},
+				},
+				Action: check,
+			},
+		},
+	}
+	if err := app.Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// loadCatalog reads the catalog and rejects entries without a type and duplicated types or codes.
+func loadCatalog(path string) ([]entry, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var list []entry
+	if err = yaml.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	types := make(map[string]bool)
+	codes := make(map[int]string)
+	for i, e := range list {
+		if !strings.HasPrefix(e.ErrType, "Err") || !token.IsIdentifier(e.ErrType) {
+			return nil, fmt.Errorf("entry %d: type %q is not an Err* identifier", i, e.ErrType)
+		}
+		if types[e.ErrType] {
+			return nil, fmt.Errorf("duplicated type %s", e.ErrType)
+		}
+		types[e.ErrType] = true
+		if other, ok := codes[e.ErrorCode]; ok {
+			return nil, fmt.Errorf("%s and %s have the same error_code %d", other, e.ErrType, e.ErrorCode)
+		}
+		codes[e.ErrorCode] = e.ErrType
+	}
+	return list, nil
+}
+
+func generate(c *cli.Context) error {
+	list, err := loadCatalog(c.String("catalog"))
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by errgen from %s. DO NOT EDIT.\n\n", filepath.Base(c.String("catalog")))
+	fmt.Fprintf(&buf, "package %s\n\n", c.String("pkg"))
+	buf.WriteString("// 错误类型，对应错误信息的type\nconst (\n")
+	for _, e := range list {
+		fmt.Fprintf(&buf, "\t%s = %q // %s\n", e.ErrType, e.ErrType, e.MessageZh)
+	}
+	buf.WriteString(")\n")
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	if err = ioutil.WriteFile(c.String("out"), src, 0644); err != nil {
+		return err
+	}
+
+	if c.String("sql") == "" {
+		return nil
+	}
+	return ioutil.WriteFile(c.String("sql"), seed(list, c.String("service"), filepath.Base(c.String("catalog"))), 0644)
+}
+
+// seed returns the SQL creating miku_errors and inserting the entries it does not have yet,
+// existing rows are overrides and are kept.
+func seed(list []entry, service, source string) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "-- Code generated by errgen from %s. DO NOT EDIT.\n", source)
+	buf.WriteString("-- 创建错误信息表并补充缺少的错误信息，已有的记录不会被覆盖\n")
+	buf.WriteString(`CREATE TABLE IF NOT EXISTS miku_errors (
+  id INT NOT NULL AUTO_INCREMENT,
+  service VARCHAR(64) NOT NULL DEFAULT '',
+  err_type VARCHAR(64) NOT NULL,
+  error_code INT NOT NULL DEFAULT 0,
+  http_status INT NOT NULL DEFAULT 0,
+  message_en VARCHAR(255) NOT NULL DEFAULT '',
+  message_zh VARCHAR(255) NOT NULL DEFAULT '',
+  messages TEXT NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY uk_err_type (err_type)
+) DEFAULT CHARSET = utf8mb4;
+`)
+	for _, e := range list {
+		if e.Service == "" {
+			e.Service = service
+		}
+		messages := "NULL"
+		if len(e.Messages) > 0 {
+			messages = quote(jsonObject(e.Messages))
+		}
+		fmt.Fprintf(&buf, "\nINSERT INTO miku_errors (service, err_type, error_code, http_status, message_en, message_zh, messages)\n"+
+			"SELECT %s, %s, %d, %d, %s, %s, %s FROM DUAL\n"+
+			"WHERE NOT EXISTS (SELECT 1 FROM miku_errors WHERE err_type = %s);\n",
+			quote(e.Service), quote(e.ErrType), e.ErrorCode, e.HttpStatus, quote(e.MessageEn), quote(e.MessageZh), messages,
+			quote(e.ErrType))
+	}
+	return buf.Bytes()
+}
+
+// quote returns a MySQL string literal.
+func quote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// jsonObject encodes the messages with sorted keys so the seed is stable.
+func jsonObject(m map[string]string) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, strconv.Quote(k)+":"+strconv.Quote(m[k]))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func check(c *cli.Context) error {
+	list, err := loadCatalog(c.String("catalog"))
+	if err != nil {
+		return err
+	}
+	consts, err := errConstants(c.String("dir"))
+	if err != nil {
+		return err
+	}
+
+	var problems []string
+	catalog := make(map[string]bool, len(list))
+	for _, e := range list {
+		catalog[e.ErrType] = true
+		if _, ok := consts[e.ErrType]; !ok {
+			problems = append(problems, fmt.Sprintf("catalog entry %s has no constant", e.ErrType))
+		}
+	}
+	for name, c := range consts {
+		if !catalog[name] {
+			problems = append(problems, fmt.Sprintf("%s: constant %s has no catalog entry", c.pos, name))
+		}
+		if c.value != strconv.Quote(name) {
+			problems = append(problems, fmt.Sprintf("%s: constant %s is %s, want %q", c.pos, name, c.value, name))
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return cli.Exit(strings.Join(problems, "\n"), 1)
+	}
+	fmt.Printf("errgen: %d constants match the catalog\n", len(consts))
+	return nil
+}
+
+// constant is an Err* declaration found by errConstants.
+type constant struct {
+	pos   string
+	value string // the quoted string literal
+}
+
+// isErrName reports whether the name is an error type such as ErrBind, ErrnoTable is not.
+func isErrName(name string) bool {
+	return len(name) > 3 && strings.HasPrefix(name, "Err") && unicode.IsUpper(rune(name[3]))
+}
+
+// errConstants returns the Err* constants and variables declared with a string literal
+// in the package directory.
+func errConstants(dir string) (map[string]constant, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string]constant)
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || (gen.Tok != token.CONST && gen.Tok != token.VAR) {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					vs := spec.(*ast.ValueSpec)
+					for i, name := range vs.Names {
+						if !isErrName(name.Name) || i >= len(vs.Values) {
+							continue
+						}
+						if lit, ok := vs.Values[i].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+							res[name.Name] = constant{pos: fset.Position(name.Pos()).String(), value: lit.Value}
+						}
+					}
+				}
+			}
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func writeCatalog(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "errno.yaml")
+	if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadCatalog(t *testing.T) {
+	list, err := loadCatalog(writeCatalog(t, `
+- type: ErrBind
+  error_code: 10001
+  http_status: 400
+  message_en: "bind"
+  message_zh: "解析失败"
+  messages:
+    ja: "バインド"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].ErrType != "ErrBind" || list[0].HttpStatus != 400 || list[0].Messages["ja"] != "バインド" {
+		t.Fatalf("loadCatalog = %+v", list)
+	}
+
+	tests := []struct {
+		name    string
+		catalog string
+		want    string
+	}{
+		{"not an identifier", "- type: Err-Bind\n  error_code: 1\n", "not an Err* identifier"},
+		{"no prefix", "- type: Bind\n  error_code: 1\n", "not an Err* identifier"},
+		{"duplicated type", "- type: ErrBind\n  error_code: 1\n- type: ErrBind\n  error_code: 2\n", "duplicated type ErrBind"},
+		{"duplicated code", "- type: ErrBind\n  error_code: 1\n- type: ErrUnknown\n  error_code: 1\n", "the same error_code 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := loadCatalog(writeCatalog(t, tt.catalog)); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("loadCatalog = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeed(t *testing.T) {
+	sql := string(seed([]entry{
+		{ErrType: "ErrBind", ErrorCode: 10001, HttpStatus: 400, MessageEn: "can't bind", MessageZh: `解析\失败`,
+			Messages: map[string]string{"ja": "バインド", "fr": "lier"}},
+		{Service: "zeus", ErrType: "ErrZeus", ErrorCode: 10002},
+	}, "miku", "errno.yaml"))
+
+	for _, want := range []string{
+		"-- Code generated by errgen from errno.yaml. DO NOT EDIT.",
+		// 字符串转义单引号及反斜杠，messages按语言排序
+		`SELECT 'miku', 'ErrBind', 10001, 400, 'can''t bind', '解析\\失败', '{"fr":"lier","ja":"バインド"}' FROM DUAL`,
+		"WHERE NOT EXISTS (SELECT 1 FROM miku_errors WHERE err_type = 'ErrBind');",
+		"SELECT 'zeus', 'ErrZeus', 10002, 0, '', '', NULL FROM DUAL",
+	} {
+		if !strings.Contains(sql, want) {
+			t.Fatalf("seed does not contain %q:\n%s", want, sql)
+		}
+	}
+}
+
+// TestCommonCatalog 检查common包的常量与内置错误信息一致，同 go generate 中的 errgen check
+func TestCommonCatalog(t *testing.T) {
+	list, err := loadCatalog("../../common/errno.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	consts, err := errConstants("../../common")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var missing []string
+	for _, e := range list {
+		if _, ok := consts[e.ErrType]; !ok {
+			missing = append(missing, e.ErrType)
+		}
+		delete(consts, e.ErrType)
+	}
+	for name := range consts {
+		missing = append(missing, name)
+	}
+	sort.Strings(missing)
+	if len(missing) > 0 {
+		t.Fatalf("constants and catalog entries without a match: %v", missing)
+	}
+}
+
+func TestIsErrName(t *testing.T) {
+	for name, want := range map[string]bool{"ErrBind": true, "ErrnoTable": false, "Err": false, "Error": false, "ErrX": true} {
+		if got := isErrName(name); got != want {
+			t.Errorf("isErrName(%s) = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package common
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"go-api/model"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// ApiKeyPrefix API Key的固定前缀
+const ApiKeyPrefix = "miku_"
+
+// apiKeyBytes API Key中随机数的字节数
+const apiKeyBytes = 32
+
+// apiKeyTouchInterval 更新最后使用时间的最小间隔
+const apiKeyTouchInterval = time.Minute
+
+// accountRoutePrefix 账户自身的接口，如刷新token、两步验证及session管理，API Key不能访问
+const accountRoutePrefix = "/v1/auth/"
+
+var errApiKey = errors.New("the api key is invalid, revoked or expired")
+
+var errApiKeyAccount = errors.New("api keys cannot access the account endpoints")
+
+//GenerateApiKey 生成新的API Key，返回明文及其哈希，明文只在创建时返回给用户
+func GenerateApiKey() (key, hash string, err error) {
+	random := make([]byte, apiKeyBytes)
+	if _, err = rand.Read(random); err != nil {
+		return
+	}
+	key = ApiKeyPrefix + base64.RawURLEncoding.EncodeToString(random)
+	hash = HashApiKey(key)
+	return
+}
+
+//HashApiKey API Key的sha256
+func HashApiKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// apiKeyCheck 校验X-Api-Key，以key所属用户的身份及key的授权范围访问
+func apiKeyCheck(c *gin.Context, key string) {
+	db := GetDB(c)
+	now := time.Now()
+
+	var apiKey model.ApiKey
+	err := db.Where("key_hash = ? AND revoked_at IS NULL", HashApiKey(key)).First(&apiKey).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && apiKey.Expired(now)) {
+		Abort(ErrTokenInvalid, errApiKey, c)
+		return
+	} else if err != nil {
+		Abort(ErrDatabase, err, c)
+		return
+	}
+	// 每次请求重新读取用户的角色，降权或删除角色后key随之失去对应的权限
+	var user model.User
+	err = db.Preload("Roles.Permissions").First(&user, apiKey.UserID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		Abort(ErrTokenInvalid, errApiKey, c)
+		return
+	} else if err != nil {
+		Abort(ErrDatabase, err, c)
+		return
+	}
+	if user.Disabled || user.Lock {
+		Abort(ErrUserStatus, nil, c)
+		return
+	}
+
+	// 记录最后使用时间，用于清理长期未使用的key
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) > apiKeyTouchInterval {
+		if err = db.Model(&apiKey).UpdateColumn("last_used_at", now).Error; err != nil {
+			LogWarnCtx(c, "update api key last_used_at failed", logrus.Fields{"err": err, "id": apiKey.ID})
+		}
+	}
+
+	c.Set("apikey_id", apiKey.ID)
+	setSessionContext(c, &Session{
+		UserID:      strconv.FormatUint(uint64(user.ID), 10),
+		UserName:    user.UserName,
+		Lock:        user.Lock,
+		Appid:       "apikey",
+		ApiKey:      true,
+		Roles:       user.RoleNames(),
+		Permissions: intersectPermissions(apiKey.ScopeList(), user.PermissionCodes()),
+	})
+}
+
+// apiKeyForbidden 通过API Key认证的session不能访问账户接口
+func apiKeyForbidden(c *gin.Context, se *Session) bool {
+	return se.ApiKey && strings.HasPrefix(c.FullPath(), accountRoutePrefix)
+}
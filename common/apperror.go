@@ -0,0 +1,116 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxStackDepth is the number of frames recorded by an AppError.
+const maxStackDepth = 32
+
+//AppError 业务错误，包含错误类型、原因、结构化字段及创建时的调用栈
+// 处理函数通过Handle返回该错误，由RenderErrors统一返回给客户端
+type AppError struct {
+	Code   string                 // 错误类型，对应错误信息的type
+	Cause  error                  // 原因，返回给客户端的detail
+	Fields map[string]interface{} // 结构化字段，返回给客户端的fields
+	stack  []uintptr
+}
+
+//NewError 创建业务错误，cause可以为nil
+func NewError(code string, cause error) *AppError {
+	return &AppError{Code: code, Cause: cause, stack: callers(1)}
+}
+
+//Errorf 创建业务错误，原因由format格式化，支持%w
+func Errorf(code string, format string, args ...interface{}) *AppError {
+	return &AppError{Code: code, Cause: fmt.Errorf(format, args...), stack: callers(1)}
+}
+
+// Error returns the code and the cause.
+func (e *AppError) Error() string {
+	if e.Cause == nil {
+		return e.Code
+	}
+	return e.Code + ": " + e.Cause.Error()
+}
+
+// Unwrap returns the cause for errors.Is and errors.As.
+func (e *AppError) Unwrap() error {
+	return e.Cause
+}
+
+// Is reports whether the target is an AppError with the same code.
+func (e *AppError) Is(target error) bool {
+	t, ok := target.(*AppError)
+	return ok && t.Code == e.Code
+}
+
+//With 添加结构化字段，返回自身以便链式调用
+func (e *AppError) With(key string, value interface{}) *AppError {
+	if e.Fields == nil {
+		e.Fields = make(map[string]interface{})
+	}
+	e.Fields[key] = value
+	return e
+}
+
+//StackTrace 返回创建错误时的调用栈
+func (e *AppError) StackTrace() []runtime.Frame {
+	var res []runtime.Frame
+	frames := runtime.CallersFrames(e.stack)
+	for {
+		frame, more := frames.Next()
+		res = append(res, frame)
+		if !more {
+			break
+		}
+	}
+	return res
+}
+
+//HandlerFunc 返回错误的处理函数，通过Handle转换为gin的处理函数
+type HandlerFunc func(c *gin.Context) error
+
+//Handle 将返回错误的处理函数转换为gin的处理函数，返回的错误由RenderErrors返回给客户端
+// 非AppError的错误作为ErrUnknown的原因
+func Handle(h HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := h(c)
+		if err == nil {
+			return
+		}
+		var appErr *AppError
+		if !errors.As(err, &appErr) {
+			appErr = &AppError{Code: ErrUnknown, Cause: err, stack: callers(0)}
+		}
+		_ = c.Error(appErr)
+		c.Abort()
+	}
+}
+
+//RenderErrors 将处理函数返回的AppError返回给客户端，已返回响应的请求不处理
+func RenderErrors(c *gin.Context) {
+	c.Next()
+
+	if c.Writer.Written() {
+		return
+	}
+	for i := len(c.Errors) - 1; i >= 0; i-- {
+		var appErr *AppError
+		if errors.As(c.Errors[i].Err, &appErr) {
+			abort(c, appErr.Code, appErr.Cause, appErr.Fields, appErr.stack)
+			return
+		}
+	}
+}
+
+// callers records the stack of the caller, skip is the number of frames above it.
+func callers(skip int) []uintptr {
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(skip+2, pcs)
+	return pcs[:n]
+}
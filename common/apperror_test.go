@@ -0,0 +1,96 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAppError(t *testing.T) {
+	cause := errors.New("connection refused")
+	err := fmt.Errorf("load user: %w", NewError(ErrDatabase, cause).With("table", "users"))
+
+	var appErr *AppError
+	if !errors.As(err, &appErr) || appErr.Code != ErrDatabase || appErr.Fields["table"] != "users" {
+		t.Fatalf("errors.As = %+v", appErr)
+	}
+	if !errors.Is(err, cause) || !errors.Is(err, NewError(ErrDatabase, nil)) || errors.Is(err, NewError(ErrBind, nil)) {
+		t.Fatalf("errors.Is does not match the cause and the code of %v", err)
+	}
+	if got := appErr.Error(); got != "ErrDatabase: connection refused" {
+		t.Fatalf("Error() = %s", got)
+	}
+	// 调用栈从创建错误的函数开始
+	if frames := appErr.StackTrace(); len(frames) == 0 || !strings.HasSuffix(frames[0].Function, "TestAppError") {
+		t.Fatalf("StackTrace()[0] = %+v", frames)
+	}
+}
+
+func TestHandle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	CONFIG = &App{}
+	tests := []struct {
+		name    string
+		handler HandlerFunc
+		status  int
+		code    string
+		detail  string
+		fields  map[string]interface{}
+		file    string // 错误的位置，非AppError没有创建位置，使用Handle
+	}{
+		{"app error", func(c *gin.Context) error {
+			return Errorf(ErrValidation, "`name` is required").With("field", "name")
+		}, http.StatusBadRequest, ErrValidation, "`name` is required", map[string]interface{}{"field": "name"}, "apperror_test.go"},
+		// 非AppError的错误作为ErrUnknown的原因
+		{"plain error", func(c *gin.Context) error {
+			return errors.New("boom")
+		}, http.StatusInternalServerError, ErrUnknown, "boom", nil, "apperror.go"},
+		{"without cause", func(c *gin.Context) error {
+			return NewError(ErrRecordNotFound, nil)
+		}, http.StatusNotFound, ErrRecordNotFound, noDetail, nil, "apperror_test.go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gin.New()
+			g.Use(RenderErrors)
+			g.GET("/v1/test", Handle(tt.handler))
+			w := httptest.NewRecorder()
+			g.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/test", nil))
+
+			var res Req
+			if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+				t.Fatal(err)
+			}
+			if w.Code != tt.status || res.Msg.ErrType != tt.code || res.Msg.Detail != tt.detail || !reflect.DeepEqual(res.Msg.Fields, tt.fields) {
+				t.Fatalf("response = %d %s, want %d %s %q %v", w.Code, w.Body, tt.status, tt.code, tt.detail, tt.fields)
+			}
+			if !strings.HasSuffix(res.Msg.File, "/"+tt.file) {
+				t.Fatalf("file = %s, want %s", res.Msg.File, tt.file)
+			}
+		})
+	}
+}
+
+func TestRenderErrorsWritten(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	CONFIG = &App{}
+	g := gin.New()
+	g.Use(RenderErrors)
+	// 已返回响应的请求不再返回错误
+	g.GET("/v1/test", Handle(func(c *gin.Context) error {
+		c.String(http.StatusAccepted, "done")
+		return NewError(ErrDatabase, nil)
+	}))
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/test", nil))
+	if w.Code != http.StatusAccepted || w.Body.String() != "done" {
+		t.Fatalf("response = %d %s", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,111 @@
+package common
+
+import (
+	"context"
+	_ "embed"
+	"strings"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// 修改errno.yaml后执行 go generate ./common 重新生成错误类型常量及 miku_errors 的初始化SQL
+//go:generate go run ../cmd/errgen generate --catalog errno.yaml --out errno.gen.go --sql ../config/sql/miku_errors_seed.sql
+//go:generate go run ../cmd/errgen check --catalog errno.yaml
+
+//errnoCatalog 编译进二进制的内置错误信息
+//
+//go:embed errno.yaml
+var errnoCatalog []byte
+
+//builtinErrors 内置错误信息，数据库不可用或缺少记录时使用
+var builtinErrors = loadErrnoCatalog(errnoCatalog)
+
+//errorMessages 当前使用的错误信息 map[string]Error，重新加载时整体替换
+var errorMessages atomic.Value
+
+//ErrorMessageMap 启动时加载的错误信息，重新加载后不会更新
+//
+// Deprecated: 使用 ErrorMessages 获取当前的错误信息。
+var ErrorMessageMap map[string]Error
+
+func init() {
+	errorMessages.Store(mergeErrors(builtinErrors, nil))
+	ErrorMessageMap = ErrorMessages()
+}
+
+//ErrorMessages 返回当前使用的错误信息，返回的map不能修改
+func ErrorMessages() map[string]Error {
+	return errorMessages.Load().(map[string]Error)
+}
+
+// loadErrnoCatalog parses the embedded catalog, a broken catalog is a build mistake so it panics.
+func loadErrnoCatalog(data []byte) map[string]Error {
+	var list []Error
+	if err := yaml.Unmarshal(data, &list); err != nil {
+		panic("common: parse errno.yaml: " + err.Error())
+	}
+	catalog := make(map[string]Error, len(list))
+	for _, v := range list {
+		catalog[v.ErrType] = v
+	}
+	return catalog
+}
+
+// mergeErrors returns the builtin errors overridden by the database rows,
+// empty columns of a row keep the builtin value.
+func mergeErrors(builtin map[string]Error, rows []Error) map[string]Error {
+	data := make(map[string]Error, len(builtin)+len(rows))
+	for k, v := range builtin {
+		data[k] = v
+	}
+	for _, v := range rows {
+		if v.ErrType == "" {
+			continue
+		}
+		base, ok := data[v.ErrType]
+		if !ok {
+			data[v.ErrType] = v
+			continue
+		}
+		base.ID = v.ID
+		if v.Service != "" {
+			base.Service = v.Service
+		}
+		if v.ErrorCode != 0 {
+			base.ErrorCode = v.ErrorCode
+		}
+		if v.HttpStatus != 0 {
+			base.HttpStatus = v.HttpStatus
+		}
+		if v.MessageEn != "" {
+			base.MessageEn = v.MessageEn
+		}
+		if v.MessageZh != "" {
+			base.MessageZh = v.MessageZh
+		}
+		if len(v.Messages) > 0 {
+			messages := make(map[string]string, len(base.Messages)+len(v.Messages))
+			for lang, msg := range base.Messages {
+				messages[lang] = msg
+			}
+			for lang, msg := range v.Messages {
+				messages[strings.ToLower(lang)] = msg
+			}
+			base.Messages = messages
+		}
+		data[v.ErrType] = base
+	}
+	return data
+}
+
+// lookupError returns the catalog entry of the code, or ErrUnknown when the code is not defined.
+func lookupError(ctx context.Context, code string) Error {
+	messages := ErrorMessages()
+	if d, ok := messages[code]; ok {
+		return d
+	}
+	LogWarnCtx(ctx, "undefined error code", logrus.Fields{"code": code})
+	return messages[ErrUnknown]
+}
@@ -0,0 +1,55 @@
+package common
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// setErrorMessages 使用合并了rows的错误信息，测试结束后恢复内置错误信息
+func setErrorMessages(t *testing.T, rows ...Error) {
+	t.Helper()
+	errorMessages.Store(mergeErrors(builtinErrors, rows))
+	t.Cleanup(func() {
+		errorMessages.Store(mergeErrors(builtinErrors, nil))
+	})
+}
+
+func TestMergeErrors(t *testing.T) {
+	builtin := map[string]Error{
+		ErrBind: {ErrType: ErrBind, ErrorCode: 10001, HttpStatus: 400, MessageEn: "bind", MessageZh: "解析失败",
+			Messages: map[string]string{"ja": "バインド"}},
+		ErrUnknown: {ErrType: ErrUnknown, ErrorCode: 10000, HttpStatus: 500, MessageEn: "unknown", MessageZh: "未知"},
+	}
+	merged := mergeErrors(builtin, []Error{
+		// 空的列保留内置的值
+		{ID: 7, ErrType: ErrBind, HttpStatus: 422, MessageEn: "invalid body", Messages: map[string]string{"FR": "corps invalide"}},
+		{ID: 8, ErrType: "ErrCustom", ErrorCode: 20000, MessageEn: "custom", MessageZh: "自定义"},
+		{ErrType: ""},
+	})
+
+	want := map[string]Error{
+		ErrBind: {ID: 7, ErrType: ErrBind, ErrorCode: 10001, HttpStatus: 422, MessageEn: "invalid body", MessageZh: "解析失败",
+			Messages: map[string]string{"ja": "バインド", "fr": "corps invalide"}},
+		ErrUnknown:  builtin[ErrUnknown],
+		"ErrCustom": {ID: 8, ErrType: "ErrCustom", ErrorCode: 20000, MessageEn: "custom", MessageZh: "自定义"},
+	}
+	if !reflect.DeepEqual(merged, want) {
+		t.Fatalf("mergeErrors = %+v, want %+v", merged, want)
+	}
+	// 合并不修改内置错误信息
+	if len(builtin[ErrBind].Messages) != 1 || builtin[ErrBind].MessageEn != "bind" {
+		t.Fatalf("the builtin entry was modified: %+v", builtin[ErrBind])
+	}
+}
+
+func TestLookupError(t *testing.T) {
+	setErrorMessages(t, Error{ErrType: ErrBind, MessageEn: "invalid body"})
+	if e := lookupError(context.Background(), ErrBind); e.MessageEn != "invalid body" || e.ErrorCode != 10001 {
+		t.Fatalf("lookupError(ErrBind) = %+v", e)
+	}
+	// 未定义的错误类型使用ErrUnknown
+	if e := lookupError(context.Background(), "ErrNotDefined"); e.ErrType != ErrUnknown {
+		t.Fatalf("lookupError of an undefined code = %+v, want ErrUnknown", e)
+	}
+}
@@ -0,0 +1,152 @@
+package common
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 内置的错误信息语言，对应Error的MessageEn及MessageZh
+const (
+	LangEn = "en"
+	LangZh = "zh"
+)
+
+//ProblemContentType RFC 7807 错误响应的Content-Type，请求的Accept包含该类型时使用
+const ProblemContentType = "application/problem+json"
+
+//defaultProblemType problem+json中type的默认前缀，后接错误类型
+const defaultProblemType = "urn:miku:errno:"
+
+//Problem RFC 7807 格式的错误响应，code、error_code及lock为扩展字段
+type Problem struct {
+	Type      string                 `json:"type"`
+	Title     string                 `json:"title"`
+	Status    int                    `json:"status"`
+	Detail    string                 `json:"detail,omitempty"`
+	Instance  string                 `json:"instance,omitempty"`
+	Code      string                 `json:"code"`
+	ErrorCode int                    `json:"error_code"`
+	Lock      bool                   `json:"lock"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
+}
+
+//Message 返回指定语言的错误信息，lang为小写的语言标签，如 en、zh-cn
+// 找不到时依次尝试主语言及英文，返回实际使用的语言
+func (e *Error) Message(lang string) (string, string) {
+	for _, l := range []string{lang, primaryLang(lang), LangEn} {
+		if msg, ok := e.message(l); ok {
+			return msg, l
+		}
+	}
+	return e.MessageEn, LangEn
+}
+
+func (e *Error) message(lang string) (string, bool) {
+	switch lang {
+	case LangEn:
+		return e.MessageEn, e.MessageEn != ""
+	case LangZh:
+		return e.MessageZh, e.MessageZh != ""
+	}
+	msg, ok := e.Messages[lang]
+	return msg, ok && msg != ""
+}
+
+//Supports 是否有指定语言的错误信息，支持主语言匹配
+func (e *Error) Supports(lang string) bool {
+	if _, ok := e.message(lang); ok {
+		return true
+	}
+	_, ok := e.message(primaryLang(lang))
+	return ok
+}
+
+// primaryLang returns the primary subtag of a language tag, `zh` for `zh-cn`.
+func primaryLang(lang string) string {
+	if i := strings.IndexByte(lang, '-'); i > 0 {
+		return lang[:i]
+	}
+	return lang
+}
+
+//ErrnoLocales 错误信息中已有的全部语言
+func ErrnoLocales() []string {
+	seen := map[string]bool{LangEn: true, LangZh: true}
+	for _, v := range ErrorMessages() {
+		for lang := range v.Messages {
+			seen[lang] = true
+		}
+	}
+	res := make([]string, 0, len(seen))
+	for lang := range seen {
+		res = append(res, lang)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// acceptLanguages parses an Accept-Language header and returns the lowercase tags
+// ordered by quality, tags with q=0 and the wildcard are skipped.
+func acceptLanguages(header string) []string {
+	var res []string
+	for _, lang := range qualityValues(header) {
+		if lang != "*" {
+			res = append(res, strings.ReplaceAll(lang, "_", "-"))
+		}
+	}
+	return res
+}
+
+// errorLanguage picks the language of the error message, the preference saved on the
+// session comes first, then the Accept-Language header. It returns "" when the client
+// asked for no language.
+func errorLanguage(c *gin.Context, e *Error) string {
+	var langs []string
+	if se := CurrentSession(c); se != nil && se.Lang != "" {
+		langs = append(langs, strings.ToLower(se.Lang))
+	}
+	langs = append(langs, acceptLanguages(c.GetHeader("Accept-Language"))...)
+	if len(langs) == 0 {
+		return ""
+	}
+	for _, lang := range langs {
+		if e.Supports(lang) {
+			return lang
+		}
+	}
+	return LangEn
+}
+
+// wantsProblem reports whether the client accepts RFC 7807 responses, a q=0 opts out.
+func wantsProblem(c *gin.Context) bool {
+	for _, mime := range qualityValues(c.GetHeader("Accept")) {
+		if mime == ProblemContentType {
+			return true
+		}
+	}
+	return false
+}
+
+// newProblem converts the response of Abort into a RFC 7807 problem.
+func newProblem(c *gin.Context, status int, req *Req) Problem {
+	typeBase := CONFIG.ErrnoProblemType
+	if typeBase == "" {
+		typeBase = defaultProblemType
+	}
+	p := Problem{
+		Type:      typeBase + req.Msg.ErrType,
+		Title:     req.Msg.Message,
+		Status:    status,
+		Instance:  c.Request.URL.Path,
+		Code:      req.Msg.ErrType,
+		ErrorCode: req.ErrorCode,
+		Lock:      req.Lock,
+		Fields:    req.Msg.Fields,
+	}
+	if req.Msg.Detail != noDetail {
+		p.Detail = req.Msg.Detail
+	}
+	return p
+}
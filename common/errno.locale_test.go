@@ -0,0 +1,101 @@
+package common
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func acceptContext(header, value string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/v1/test", nil)
+	if value != "" {
+		c.Request.Header.Set(header, value)
+	}
+	return c
+}
+
+func TestWantsProblem(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   bool
+	}{
+		{"", false},
+		{"application/json", false},
+		{ProblemContentType, true},
+		{"application/json, Application/Problem+JSON;q=0.5", true},
+		{"application/problem+json;q=0", false},
+		{"application/problem+json; q=0.0, application/json", false},
+	}
+	for _, tt := range tests {
+		if got := wantsProblem(acceptContext("Accept", tt.accept)); got != tt.want {
+			t.Errorf("wantsProblem(%q) = %v, want %v", tt.accept, got, tt.want)
+		}
+	}
+}
+
+func TestErrorLanguage(t *testing.T) {
+	e := &Error{MessageEn: "Permission denied.", MessageZh: "没有权限", Messages: map[string]string{"ja": "権限がありません"}}
+	tests := []struct {
+		name    string
+		accept  string
+		session string
+		want    string
+	}{
+		{"no preference", "", "", ""},
+		{"exact", "ja", "", "ja"},
+		{"primary language", "zh-CN", "", "zh-cn"},
+		{"quality", "fr, ja;q=0.5, zh;q=0.8", "", "zh"},
+		{"wildcard only", "*", "", ""},
+		// 不支持的语言使用英文
+		{"unsupported", "fr, de;q=0.5", "", LangEn},
+		{"session first", "zh", "ja", "ja"},
+		{"unsupported session", "", "fr", LangEn},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := acceptContext("Accept-Language", tt.accept)
+			if tt.session != "" {
+				c.Set("session", &Session{Lang: tt.session})
+			}
+			if got := errorLanguage(c, e); got != tt.want {
+				t.Fatalf("errorLanguage = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// 选择的语言没有信息时依次使用主语言及英文
+	if msg, lang := e.Message("zh-tw"); msg != "没有权限" || lang != LangZh {
+		t.Fatalf("Message(zh-tw) = %s, %s", msg, lang)
+	}
+	if msg, lang := e.Message("fr"); msg != "Permission denied." || lang != LangEn {
+		t.Fatalf("Message(fr) = %s, %s", msg, lang)
+	}
+}
+
+func TestAbortProblem(t *testing.T) {
+	CONFIG = &App{}
+	w := abortRequest(ErrPermission, http.Header{"Accept": {ProblemContentType}, "Accept-Language": {"zh-CN"}})
+	if ct := w.Header().Get("Content-Type"); ct != ProblemContentType+"; charset=utf-8" {
+		t.Fatalf("Content-Type = %s", ct)
+	}
+	var p Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	want := Problem{
+		Type:      defaultProblemType + ErrPermission,
+		Title:     "没有权限",
+		Status:    http.StatusForbidden,
+		Instance:  "/v1/test",
+		Code:      ErrPermission,
+		ErrorCode: 10075,
+	}
+	if w.Code != http.StatusForbidden || !reflect.DeepEqual(p, want) {
+		t.Fatalf("problem = %d %+v, want %+v", w.Code, p, want)
+	}
+}
@@ -0,0 +1,100 @@
+package common
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+//ErrnoTable 保存错误信息的数据表
+const ErrnoTable = "miku_errors"
+
+//errnoChannel 通知其他实例重新加载错误信息的Redis频道
+const errnoChannel = "miku_errno_reload"
+
+//errnoRetry 订阅断开后的重试间隔
+const errnoRetry = 5 * time.Second
+
+//errnoDB 加载错误信息使用的数据库连接
+var errnoDB *gorm.DB
+
+//errnoMu 保证重新加载按顺序执行，避免旧数据覆盖新数据
+var errnoMu sync.Mutex
+
+//ReloadErrnoMessages 从数据库重新加载错误信息，与内置错误信息合并后整体替换
+func ReloadErrnoMessages() error {
+	if errnoDB == nil {
+		return nil
+	}
+	errnoMu.Lock()
+	defer errnoMu.Unlock()
+
+	var errorList []Error
+	if err := errnoDB.Table(ErrnoTable).Find(&errorList).Error; err != nil {
+		return err
+	}
+	errorMessages.Store(mergeErrors(builtinErrors, errorList))
+	return nil
+}
+
+//PublishErrnoReload 通知所有实例重新加载错误信息，未使用Redis时只有当前实例
+func PublishErrnoReload(ctx context.Context) {
+	if _, ok := Store.(*redisStore); !ok {
+		return
+	}
+	conn := Pool.Get()
+	defer conn.Close()
+	if _, err := conn.Do("PUBLISH", errnoChannel, time.Now().UnixNano()); err != nil {
+		LogErrorCtx(ctx, "publish errno reload failed", logrus.Fields{"err": err})
+	}
+}
+
+//ErrnoWatch 启动定时加载及Redis订阅，使各实例的错误信息保持一致
+func ErrnoWatch() {
+	if CONFIG.ErrnoReload > 0 {
+		go func() {
+			ticker := time.NewTicker(time.Duration(CONFIG.ErrnoReload) * time.Second)
+			defer ticker.Stop()
+			for range ticker.C {
+				if err := ReloadErrnoMessages(); err != nil {
+					LogWarnf("reload errno messages failed", logrus.Fields{"err": err})
+				}
+			}
+		}()
+	}
+	if _, ok := Store.(*redisStore); ok {
+		go func() {
+			for {
+				if err := errnoSubscribe(); err != nil {
+					LogWarnf("errno reload subscription failed", logrus.Fields{"err": err})
+				}
+				time.Sleep(errnoRetry)
+			}
+		}()
+	}
+}
+
+// errnoSubscribe reloads the messages on every notification until the connection fails.
+func errnoSubscribe() error {
+	psc := redis.PubSubConn{Conn: Pool.Get()}
+	defer psc.Close()
+	if err := psc.Subscribe(errnoChannel); err != nil {
+		return err
+	}
+	for {
+		switch v := psc.Receive().(type) {
+		case redis.Message:
+			if err := ReloadErrnoMessages(); err != nil {
+				LogWarnf("reload errno messages failed", logrus.Fields{"err": err})
+			} else {
+				LogInfo("errno messages reloaded")
+			}
+		case error:
+			return v
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package common
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// etagWriter buffers the response so the middleware can hash it before it is sent.
+// Responses with another status than 200 have no ETag and are passed through.
+type etagWriter struct {
+	gin.ResponseWriter
+	status      int
+	body        bytes.Buffer
+	passthrough bool
+}
+
+func (w *etagWriter) WriteHeader(code int) {
+	if code <= 0 || w.passthrough {
+		return
+	}
+	w.status = code
+	if code != http.StatusOK && w.body.Len() == 0 {
+		w.passthrough = true
+		if code == http.StatusNotModified {
+			revalidate(w.Header())
+		}
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (w *etagWriter) WriteHeaderNow() {
+	if w.passthrough {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func (w *etagWriter) Write(data []byte) (int, error) {
+	if w.passthrough {
+		return w.ResponseWriter.Write(data)
+	}
+	return w.body.Write(data)
+}
+
+func (w *etagWriter) WriteString(s string) (int, error) {
+	if w.passthrough {
+		return w.ResponseWriter.WriteString(s)
+	}
+	return w.body.WriteString(s)
+}
+
+func (w *etagWriter) Status() int {
+	if w.passthrough {
+		return w.ResponseWriter.Status()
+	}
+	return w.status
+}
+
+func (w *etagWriter) Size() int {
+	if w.passthrough {
+		return w.ResponseWriter.Size()
+	}
+	if w.body.Len() == 0 {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *etagWriter) Written() bool {
+	return w.passthrough || w.body.Len() > 0
+}
+
+// Flush sends the passed through response, a buffered body is sent once the handler returns.
+func (w *etagWriter) Flush() {
+	if w.passthrough {
+		w.ResponseWriter.Flush()
+	}
+}
+
+// ETag is a middleware function that sets a strong ETag computed from the response body
+// of successful GET and HEAD requests, and answers 304 when it matches If-None-Match.
+// Use it on the routes that need it, it buffers the whole 200 response.
+func ETag(c *gin.Context) {
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		c.Next()
+		return
+	}
+	origin := c.Writer
+	w := &etagWriter{ResponseWriter: origin, status: http.StatusOK}
+	c.Writer = w
+	c.Next()
+	c.Writer = origin
+
+	// 未写入响应时交给后续的中间件（如RenderErrors）处理，非200的响应已直接写入
+	if w.passthrough || w.body.Len() == 0 {
+		return
+	}
+	header := origin.Header()
+	revalidate(header)
+	etag := header.Get("ETag")
+	if etag == "" {
+		sum := sha256.Sum256(w.body.Bytes())
+		etag = `"` + hex.EncodeToString(sum[:16]) + `"`
+		header.Set("ETag", etag)
+	}
+
+	if inm := c.GetHeader("If-None-Match"); inm != "" {
+		if etagMatch(inm, etag) {
+			notModified(origin)
+			return
+		}
+	} else if lastModified, ok := c.Get(lastModifiedKey); ok && !modifiedSince(c, lastModified.(time.Time)) {
+		notModified(origin)
+		return
+	}
+	flushResponse(origin, w)
+}
+
+// lastModifiedKey is the context key of the time given to LastModified.
+const lastModifiedKey = "last-modified"
+
+// LastModified sets the Last-Modified header of the response. It answers 304 and returns true
+// when the client copy is still fresh, the handler should then return without a body.
+// It replaces the value set by NoCache.
+func LastModified(c *gin.Context, t time.Time) bool {
+	t = t.UTC().Truncate(time.Second)
+	c.Set(lastModifiedKey, t)
+	c.Header("Last-Modified", t.Format(http.TimeFormat))
+	// If-None-Match优先，由ETag中间件判断
+	if c.GetHeader("If-None-Match") != "" || modifiedSince(c, t) {
+		return false
+	}
+	c.AbortWithStatus(http.StatusNotModified)
+	return true
+}
+
+// modifiedSince reports whether t is after If-Modified-Since, true without the header.
+func modifiedSince(c *gin.Context, t time.Time) bool {
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		return true
+	}
+	since, err := http.ParseTime(c.GetHeader("If-Modified-Since"))
+	if err != nil {
+		return true
+	}
+	return t.After(since)
+}
+
+// etagMatch compares the If-None-Match list with the etag, using the weak comparison.
+func etagMatch(header, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// revalidate lets the client cache a validated response, the no-store of NoCache would
+// prevent it, so the client has to revalidate instead.
+func revalidate(header http.Header) {
+	if strings.Contains(header.Get("Cache-Control"), "no-store") {
+		header.Set("Cache-Control", "no-cache, must-revalidate")
+	}
+}
+
+// notModified sends a 304 without the representation headers.
+func notModified(w gin.ResponseWriter) {
+	header := w.Header()
+	header.Del("Content-Type")
+	header.Del("Content-Length")
+	w.WriteHeader(http.StatusNotModified)
+	w.WriteHeaderNow()
+}
+
+// flushResponse sends the buffered response as it was written by the handler.
+func flushResponse(w gin.ResponseWriter, buf *etagWriter) {
+	w.WriteHeader(buf.status)
+	w.WriteHeaderNow()
+	if buf.body.Len() > 0 {
+		_, _ = w.Write(buf.body.Bytes())
+	}
+}
@@ -0,0 +1,137 @@
+package common
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var testModified = time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+
+func etagRequest(g *gin.Engine, method, path string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, req)
+	return w
+}
+
+func setupETag(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	g := gin.New()
+	g.Use(NoCache, ETag)
+	g.GET("/v1/test", func(c *gin.Context) {
+		c.String(http.StatusOK, "hello")
+	})
+	g.GET("/v1/modified", func(c *gin.Context) {
+		if LastModified(c, testModified) {
+			return
+		}
+		c.String(http.StatusOK, "hello")
+	})
+	g.POST("/v1/test", func(c *gin.Context) {
+		c.String(http.StatusOK, "hello")
+	})
+	return g
+}
+
+func TestETag(t *testing.T) {
+	g := setupETag(t)
+	w := etagRequest(g, http.MethodGet, "/v1/test", nil)
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || w.Body.String() != "hello" || etag == "" {
+		t.Fatalf("response = %d %q, ETag %q", w.Code, w.Body, etag)
+	}
+	if cc := w.Header().Get("Cache-Control"); cc != "no-cache, must-revalidate" {
+		t.Fatalf("Cache-Control = %s", cc)
+	}
+
+	tests := []struct {
+		name   string
+		inm    string
+		status int
+	}{
+		{"match", etag, http.StatusNotModified},
+		{"weak", "W/" + etag, http.StatusNotModified},
+		{"list", `"other", ` + etag, http.StatusNotModified},
+		{"wildcard", "*", http.StatusNotModified},
+		{"changed", `"other"`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := etagRequest(g, http.MethodGet, "/v1/test", http.Header{"If-None-Match": {tt.inm}})
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d", w.Code, tt.status)
+			}
+			if tt.status == http.StatusNotModified && (w.Body.Len() != 0 || w.Header().Get("Content-Type") != "") {
+				t.Fatalf("304 with body %q and Content-Type %q", w.Body, w.Header().Get("Content-Type"))
+			}
+		})
+	}
+
+	// 只处理GET及HEAD请求
+	if w := etagRequest(g, http.MethodPost, "/v1/test", nil); w.Header().Get("ETag") != "" {
+		t.Fatalf("POST ETag = %s", w.Header().Get("ETag"))
+	}
+}
+
+func TestLastModified(t *testing.T) {
+	g := setupETag(t)
+	tests := []struct {
+		name   string
+		header http.Header
+		status int
+	}{
+		{"no header", nil, http.StatusOK},
+		{"modified", http.Header{"If-Modified-Since": {testModified.Add(-time.Second).Format(http.TimeFormat)}}, http.StatusOK},
+		{"same time", http.Header{"If-Modified-Since": {testModified.Format(http.TimeFormat)}}, http.StatusNotModified},
+		{"not modified", http.Header{"If-Modified-Since": {testModified.Add(time.Hour).Format(http.TimeFormat)}}, http.StatusNotModified},
+		{"invalid", http.Header{"If-Modified-Since": {"yesterday"}}, http.StatusOK},
+		// If-None-Match优先于If-Modified-Since
+		{"etag first", http.Header{
+			"If-Modified-Since": {testModified.Format(http.TimeFormat)},
+			"If-None-Match":     {`"other"`},
+		}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := etagRequest(g, http.MethodGet, "/v1/modified", tt.header)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d", w.Code, tt.status)
+			}
+			if got := w.Header().Get("Last-Modified"); got != testModified.Format(http.TimeFormat) {
+				t.Fatalf("Last-Modified = %s", got)
+			}
+			if (tt.status == http.StatusNotModified) != (w.Body.Len() == 0) {
+				t.Fatalf("status %d with body %q", w.Code, w.Body)
+			}
+		})
+	}
+}
+
+func TestETagPassthrough(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	var written string
+	g := gin.New()
+	g.Use(ETag)
+	g.GET("/v1/test", func(c *gin.Context) {
+		c.String(http.StatusNotFound, "missing")
+		// 非200的响应不缓冲，处理函数返回前已写入
+		written = rec.Body.String()
+	})
+	g.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/test", nil))
+
+	if written != "missing" {
+		t.Fatalf("body written before the handler returned = %q, want missing", written)
+	}
+	if rec.Code != http.StatusNotFound || rec.Body.String() != "missing" || rec.Header().Get("ETag") != "" {
+		t.Fatalf("response = %d %q, ETag %q", rec.Code, rec.Body, rec.Header().Get("ETag"))
+	}
+}
@@ -2,13 +2,13 @@ package common
 
 import (
 	"github.com/gin-gonic/gin"
-	"github.com/satori/go.uuid"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
-var REQUEST_ID string
-
 // NoCache is a middleware function that appends headers
 // to prevent the client from caching the HTTP response.
 func NoCache(c *gin.Context) {
@@ -23,12 +23,12 @@ func NoCache(c *gin.Context) {
 // chain and ends the request.
 func Options(c *gin.Context) {
 	if c.Request.Method != "OPTIONS" {
-		c.Header("Access-Control-Expose-Headers", "X-Request-Id,X-Total-Count")
+		c.Header("Access-Control-Expose-Headers", "X-Request-Id,X-Total-Count,Session,X-Auth-Token,X-Session-Expires-In,X-Session-Lifetime,ETag")
 		c.Next()
 	} else {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET,POST,PUT,PATCH,DELETE,OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "session, origin, content-type, accept,appid")
+		c.Header("Access-Control-Allow-Headers", "session, authorization, x-api-key, origin, content-type, accept,appid, if-none-match, if-modified-since")
 		c.Header("Allow", "HEAD,GET,POST,PUT,PATCH,DELETE,OPTIONS")
 		c.Header("Content-Type", "application/json")
 		c.AbortWithStatus(http.StatusOK)
@@ -47,11 +47,38 @@ func Secure(c *gin.Context) {
 	}
 }
 
-// RequestId is a middleware function that appends the id of request.
-func RequestId(c *gin.Context) {
-	UUID := uuid.NewV4()
-	REQUEST_ID = UUID.String()
-	c.Set("request-id", REQUEST_ID)
-	c.Header("X-Request-Id", REQUEST_ID)
-	c.Next()
+// qualityValues parses a header such as Accept or Accept-Language and returns the lowercase
+// values ordered by their q parameter, values with q=0 are skipped.
+func qualityValues(header string) []string {
+	type value struct {
+		v string
+		q float64
+	}
+	var values []value
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		v := strings.ToLower(strings.TrimSpace(params[0]))
+		if v == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range params[1:] {
+			if p := strings.TrimSpace(param); strings.HasPrefix(p, "q=") {
+				if f, err := strconv.ParseFloat(p[2:], 64); err == nil {
+					q = f
+				}
+			}
+		}
+		if q > 0 {
+			values = append(values, value{v: v, q: q})
+		}
+	}
+	sort.SliceStable(values, func(i, j int) bool {
+		return values[i].q > values[j].q
+	})
+	res := make([]string, 0, len(values))
+	for _, v := range values {
+		res = append(res, v.v)
+	}
+	return res
 }
@@ -13,134 +13,8 @@ import (
 	"gorm.io/gorm"
 )
 
-// ErrorMessageMap var PrivilegeChangeMark sync.Map
-var ErrorMessageMap map[string]Error
-var (
-	ErrBind                 = "ErrBind"
-	ErrValidation           = "ErrValidation"
-	ErrEncrypt              = "ErrEncrypt"
-	ErrDatabase             = "ErrDatabase"
-	ErrRecordNotFound       = "ErrRecordNotFound"
-	ErrTokenInvalid         = "ErrTokenInvalid"
-	ErrIamForbidden         = "ErrIamForbidden"
-	ErrUserIncorrect        = "ErrUserIncorrect"
-	ErrTokenParse           = "ErrTokenParse"
-	ErrTokenSign            = "ErrTokenSign"
-	ErrMissingAuthorization = "ErrMissingAuthorization"
-	ErrRateLimit            = "ErrRateLimit"
-	ErrDBProxy              = "ErrDBProxy"
-	ErrMonitorProxy         = "ErrMonitorProxy"
-	ErrInstanceNotFound     = "ErrInstanceNotFound"
-	ErrAnthenaProxy         = "ErrAnthenaProxy"
-	ErrZeusProxy            = "ErrZeusProxy"
-	ErrCreateLogMonitor     = "ErrCreateLogMonitor"
-	ErrLogMonitorNotFound   = "ErrLogMonitorNotFound"
-	ErrSSHProxy             = "ErrSSHProxy"
-	ErrMissingHeader        = errors.New("The length of the `Authorization` header is zero.")
-	ErrDecode               = "ErrDecode"
-	ErrDuplicate            = "ErrDuplicate"
-	ErrConnectFailed        = "ErrConnectFailed"
-	ErrConnectError         = "ErrConnectError"
-	ErrDatabaseType         = "ErrDatabaseType"
-	ErrResponse             = "ErrResponse"
-	ErrSession              = "ErrSession"
-	ErrConnectorPause       = "ErrConnectorPause"
-	ErrConnectorStart       = "ErrConnectorStart"
-	ErrPasswordRule         = "ErrPasswordRule"
-	ErrPasswordExpired      = "ErrPasswordExpired"
-	ErrCreateInstance       = "ErrCreateInstance"
-	ErrUpdateInstance       = "ErrUpdateInstance"
-	ErrDeleteInstance       = "ErrDeleteInstance"
-	ErrCreateInstanceGroup  = "ErrCreateInstanceGroup"
-	ErrQueryInstanceGroup   = "ErrQueryInstanceGroup"
-	ErrUpdateInstanceGroup  = "ErrUpdateInstanceGroup"
-	ErrDeleteInstanceGroup  = "ErrDeleteInstanceGroup"
-	ErrReadFile             = "ErrReadFile"
-	ErrGoogleVerify         = "ErrGoogleVerify"
-	ErrWindowsAdError       = "ErrWindowsAdError"
-	ErrWindowsADFailed      = "ErrWindowsADFailed"
-	ErrCreateDraftBox       = "ErrCreateDraftBox"
-	ErrDeleteDraftBox       = "ErrDeleteDraftBox"
-	ErrDeleteTemplate       = "ErrDeleteTemplate"
-	ErrUpdateTemplate       = "ErrUpdateTemplate"
-
-	ErrCreateWebsocket    = "ErrCreateWebsocket"
-	ErrValidate           = "ErrValidate"
-	ErrQueryTask          = "ErrQueryTask"
-	ErrBuildParams        = "ErrBuildParams"
-	ErrExecSql            = "ErrExecSql"
-	ErrQueryOverSql       = "ErrQueryOverSql"
-	ErrTaskIsStillRunning = "ErrTaskIsStillRunning"
-	ErrCreateTask         = "ErrCreateTask"
-	ErrAnalyzeSql         = "ErrAnalyzeSql"
-	ErrUpdateTask         = "ErrUpdateTask"
-	ErrDeleteTask         = "ErrDeleteTask"
-	ErrUserStatus         = "ErrUserStatus"
-	ErrNodeFailed         = "ErrNodeFailed"
-	ErrNodeError          = "ErrNodeError"
-
-	ErrSupportDataBase  = "ErrSupportDataBase"
-	ErrGetCurrentSchema = "ErrGetCurrentSchema"
-	ErrSetCurrentSchema = "ErrSetCurrentSchema"
-
-	ErrCreateColumnReflect = "ErrCreateColumnReflect"
-	ErrUpdateColumnReflect = "ErrUpdateColumnReflect"
-
-	ErrCancelTask          = "ErrCancelTask"
-	ErrOutOfLimit          = "ErrOutOfLimit"
-	ErrSupportSqlClassType = "ErrSupportSqlClassType"
-	ErrUpdateDraftBox      = "ErrUpdateDraftBox"
-	ErrCreateUUID          = "ErrCreateUUID"
-	ErrCreateExportTask    = "ErrCreateExportTask"
-	ErrUpdateExportTask    = "ErrUpdateExportTask"
-	ErrImportData          = "ErrImportData"
-	ErrGetExportTask       = "ErrGetExportTask"
-	ErrPermission          = "ErrPermission"
-	ErrConnectToDatabase   = "ErrConnectToDatabase"
-	ErrRemoveFile          = "ErrRemoveFile"
-	ErrFileIsNotExist      = "ErrFileIsNotExist"
-	ErrReloadExportTask    = "ErrReloadExportTask"
-	ErrCloseConnection     = "ErrCloseConnection"
-	ErrGetExportFile       = "ErrGetExportFile"
-
-	ErrCreateOrganization = "ErrCreateOrganization"
-	ErrUpdateOrganization = "ErrUpdateOrganization"
-
-	ErrBindUserToInstance = "ErrBindUserToInstance"
-	ErrUserAccess         = "ErrUserAccess"
-	ErrGetAccess          = "ErrGetAccess"
-
-	ErrDownloadOperationLog  = "ErrDownloadOperationLog"
-	ErrSendNotify            = "ErrSendNotify"
-	ErrSendTestNotify        = "ErrSendTestNotify"
-	ErrCreateNotify          = "ErrCreateNotify"
-	ErrCreatePubSubConn      = "ErrCreatePubSubConn"
-	ErrSubscribeNotify       = "ErrSubscribeNotify"
-	ErrReceiveNotify         = "ErrReceiveNotify"
-	ErrBindRestrictWithUser  = "ErrBindRestrictWithUser"
-	ErrDeleteRestrictAccess  = "ErrDeleteRestrictAccess"
-	ErrRestrictAccessInvalid = "ErrRestrictAccessInvalid"
-
-	ErrInterceptRule        = "ErrInterceptRule"
-	ErrInterceptRuleCheck   = "ErrInterceptRuleCheck"
-	ErrInterceptRuleExecute = "ErrInterceptRuleExecute"
-
-	ErrCreateDataMasking = "ErrCreateDataMasking"
-
-	ErrLicenseExpired       = "ErrLicenseExpired"
-	ErrLicenseAuthorization = "ErrLicenseAuthorization"
-	ErrActivation           = "ErrActivation"
-	ErrLicenseParse         = "ErrLicenseParse"
-	ErrKillSession          = "ErrKillSession"
-	ErrGenerateSuggestion   = "ErrGenerateSuggestion"
-
-	ErrIgnoreErrRestrictAccess = "ErrIgnoreErrRestrictAccess"
-	ErrIgnoreErrDataMasking    = "ErrIgnoreErrDataMasking"
-
-	ErrIpAccess            = "ErrIpAccess"
-	ErrSaveIpAccessSetting = "ErrSaveIpAccessSetting"
-	ErrLoginIpAccess       = "ErrLoginIpAccess"
-)
+//ErrMissingHeader 缺少认证头，其他错误类型由errgen根据errno.yaml生成在errno.gen.go
+var ErrMissingHeader = errors.New("The length of the `Authorization` header is zero.")
 
 type Req struct {
 	ErrorCode int         `json:"error_code" example:"0"`
@@ -155,48 +29,81 @@ type Req struct {
 	SuccessReturn(PageData={result,total})
 */
 type PageData struct {
-	Total  int64       `json:"total" default:"0"` // 查询总数
-	Result interface{} `json:"result"`            // 查询结果分页数据
+	Total      int64       `json:"total" default:"0"`     // 查询总数，游标分页不查询总数，固定为0
+	Result     interface{} `json:"result"`                // 查询结果分页数据
+	NextCursor string      `json:"next_cursor,omitempty"` // 游标分页时下一页的游标，为空时已是最后一页
 }
 
 type Error struct {
-	ID        int    `json:"id"`
-	Service   string `json:"service"`
-	ErrType   string `json:"type"`
-	ErrorCode int    `json:"error_code"`
-	MessageEn string `json:"message_en"`
-	MessageZh string `json:"message_zh"`
+	ID         int    `json:"id" yaml:"-"`
+	Service    string `json:"service" yaml:"service"`
+	ErrType    string `json:"type" yaml:"type"`
+	ErrorCode  int    `json:"error_code" yaml:"error_code"`
+	HttpStatus int    `json:"http_status" yaml:"http_status"` // 返回的HTTP状态码，为0或不在100至599之间时返回500
+	MessageEn  string `json:"message_en" yaml:"message_en"`
+	MessageZh  string `json:"message_zh" yaml:"message_zh"`
+	// 其他语言的错误信息，key为小写的语言标签，如 ja、zh-tw
+	Messages map[string]string `json:"messages,omitempty" yaml:"messages" gorm:"serializer:json"`
+}
+
+// Status returns the http status of the error, 500 when it is not defined or invalid.
+func (e *Error) Status() int {
+	if e.HttpStatus < 100 || e.HttpStatus > 599 {
+		return http.StatusInternalServerError
+	}
+	return e.HttpStatus
 }
 
+// noDetail is the detail of errors aborted without a cause.
+const noDetail = "No more detail, see `Message`."
+
 // Err represents an error, `Code`, `File`, `Line`, `Func` will be automatically filled.
+// Only `Message` in the requested language is returned when the client sends Accept-Language.
 type Err struct {
-	ErrType   string `json:"code" example:"ErrNone"`
-	MessageEn string `json:"message_en" example:"this is an error"`
-	MessageZh string `json:"message_zh" example:"这是一个错误"`
-	Detail    string `json:"detail" example:"xxx"`
-	File      string `json:"file" example:"test.go"`
-	Line      int    `json:"line" example:"111"`
-	Func      string `json:"func" example:"/golang/test.go/test():123"`
+	ErrType   string                 `json:"code" example:"ErrNone"`
+	Message   string                 `json:"message" example:"this is an error"`
+	Lang      string                 `json:"lang,omitempty" example:"en"`
+	MessageEn string                 `json:"message_en,omitempty" example:"this is an error"`
+	MessageZh string                 `json:"message_zh,omitempty" example:"这是一个错误"`
+	Detail    string                 `json:"detail" example:"xxx"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
+	File      string                 `json:"file" example:"test.go"`
+	Line      int                    `json:"line" example:"111"`
+	Func      string                 `json:"func" example:"/golang/test.go/test():123"`
 }
 
 // Error returns the error message.
 func (e *Err) Error() string {
-	return e.MessageEn
+	return e.Message
 }
 
-// Fill the error struct with the detail error information.
-func fill(e *Err) *Err {
-	// Fill the error occurred path, line, code.
-	pc, fn, line, _ := runtime.Caller(2)
-	e.File = strings.Replace(fn, os.Getenv("GOPATH")+"/src/", "", -1)
-	e.Line = line
-	f := runtime.FuncForPC(pc).Name()
-	e.Func = strings.Split(f, ".")[1] + "()"
+// Fill the error struct with the location of the first frame of the stack.
+func fill(e *Err, stack []uintptr) *Err {
+	frame, _ := runtime.CallersFrames(stack).Next()
+	e.File = strings.Replace(frame.File, os.Getenv("GOPATH")+"/src/", "", -1)
+	e.Line = frame.Line
+	e.Func = funcName(frame.Function) + "()"
 	return e
 }
 
+// funcName strips the package path of a function name, `(*T).M` for `go-api/api.(*T).M`.
+func funcName(name string) string {
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		name = name[i+1:]
+	}
+	if i := strings.IndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
 // Abort the current request with the specified error code.
 func Abort(code string, err error, c *gin.Context) {
+	abort(c, code, err, nil, callers(1))
+}
+
+// abort renders the error, the location is taken from the first frame of the stack.
+func abort(c *gin.Context, code string, err error, fields map[string]interface{}, stack []uintptr) {
 	isLock := c.Value("lock")
 
 	if isLock == nil {
@@ -204,27 +111,41 @@ func Abort(code string, err error, c *gin.Context) {
 	}
 
 	var req Req
-	d := ErrorMessageMap[code]
+	d := lookupError(c, code)
 	req.ErrorCode = d.ErrorCode
 
 	// Get error StatusCode, Code, Message from errno.ERROR_MESSAGE
 	req.Msg.ErrType = d.ErrType
-	req.Msg.MessageEn = d.MessageEn
-	req.Msg.MessageZh = d.MessageZh
+	if lang := errorLanguage(c, &d); lang != "" {
+		req.Msg.Message, req.Msg.Lang = d.Message(lang)
+	} else {
+		// 未指定语言时同时返回中英文
+		req.Msg.Message = d.MessageEn
+		req.Msg.MessageEn = d.MessageEn
+		req.Msg.MessageZh = d.MessageZh
+	}
+	req.Msg.Fields = fields
 	req.Lock = isLock.(bool)
 	if err == nil {
-		err = errors.New(req.Msg.MessageEn)
-		req.Msg.Detail = "No more detail, see `Message`."
+		err = errors.New(d.MessageEn)
+		req.Msg.Detail = noDetail
 	} else {
 		req.Msg.Detail = err.Error()
 	}
 
 	_ = c.Error(err)
-	_ = c.Error(fill(&req.Msg))
-	c.JSON(http.StatusInternalServerError, req)
+	_ = c.Error(fill(&req.Msg, stack))
+	if wantsProblem(c) {
+		c.Header("Content-Type", ProblemContentType+"; charset=utf-8")
+		c.JSON(d.Status(), newProblem(c, d.Status(), &req))
+	} else {
+		c.JSON(d.Status(), req)
+	}
 	c.Abort()
 }
 
+// SuccessReturn returns the result in the format chosen by the `format` query param or the
+// Accept header: json (default), msgpack, yaml or csv. It answers 406 when none matches.
 func SuccessReturn(result interface{}, c *gin.Context) {
 	isLock := c.Value("lock")
 
@@ -237,22 +158,17 @@ func SuccessReturn(result interface{}, c *gin.Context) {
 	req.ErrorCode = 0
 	req.Lock = isLock.(bool)
 
-	c.JSON(http.StatusOK, req)
+	renderReq(c, http.StatusOK, &req)
 }
 
-// GetErrnoMessages getErrnoMessages get the errno message.
+// GetErrnoMessages get the errno messages from the database and merge them into the builtin catalog.
+// The builtin catalog is used alone when the table can not be read.
 func GetErrnoMessages(DB *gorm.DB) {
-	var errorList []Error
-
-	if err := DB.Raw("select * from miku_errors").Find(&errorList).Error; err != nil {
-		LogFatalf("Get errno messags failed.", logrus.Fields{
+	errnoDB = DB
+	if err := ReloadErrnoMessages(); err != nil {
+		LogWarnf("Get errno messags failed, use the builtin messages.", logrus.Fields{
 			"error": err.Error(),
 		})
 	}
-	data := make(map[string]Error)
-	for _, v := range errorList {
-		data[v.ErrType] = v
-	}
-
-	ErrorMessageMap = data
+	ErrorMessageMap = ErrorMessages()
 }
@@ -0,0 +1,75 @@
+package common
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestErrorStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		want   int
+	}{
+		{0, http.StatusInternalServerError},
+		{42, http.StatusInternalServerError},
+		{600, http.StatusInternalServerError},
+		{http.StatusNotFound, http.StatusNotFound},
+		{http.StatusTooManyRequests, http.StatusTooManyRequests},
+	}
+	for _, tt := range tests {
+		if got := (&Error{HttpStatus: tt.status}).Status(); got != tt.want {
+			t.Errorf("Status() with http_status %d = %d, want %d", tt.status, got, tt.want)
+		}
+	}
+}
+
+// abortRequest 调用Abort并返回响应
+func abortRequest(code string, header http.Header) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	g := gin.New()
+	g.GET("/v1/test", func(c *gin.Context) {
+		Abort(code, nil, c)
+	})
+	req := httptest.NewRequest(http.MethodGet, "/v1/test", nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, req)
+	return w
+}
+
+func TestAbort(t *testing.T) {
+	CONFIG = &App{}
+	tests := []struct {
+		code   string
+		status int
+		errno  int
+	}{
+		{ErrBind, http.StatusBadRequest, 10001},
+		{ErrSession, http.StatusUnauthorized, 10027},
+		{ErrPermission, http.StatusForbidden, 10075},
+		{ErrRecordNotFound, http.StatusNotFound, 10005},
+		{"ErrNotDefined", http.StatusInternalServerError, 10000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			w := abortRequest(tt.code, nil)
+			var res Req
+			if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+				t.Fatal(err)
+			}
+			if w.Code != tt.status || res.ErrorCode != tt.errno {
+				t.Fatalf("Abort(%s) = %d %s, want %d with error_code %d", tt.code, w.Code, w.Body, tt.status, tt.errno)
+			}
+			// 未指定语言时同时返回中英文
+			if res.Msg.MessageEn == "" || res.Msg.MessageZh == "" || res.Msg.Lang != "" {
+				t.Fatalf("messages = %+v", res.Msg)
+			}
+		})
+	}
+}
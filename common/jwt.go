@@ -0,0 +1,221 @@
+package common
+
+import (
+	"crypto/rsa"
+	"errors"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+)
+
+// 认证方式
+const (
+	AuthModeSession = "session" // 仅使用Redis session
+	AuthModeJwt     = "jwt"     // 仅使用JSON Web Token
+	AuthModeBoth    = "both"    // 两者均可
+)
+
+// TokenRevoked 记录用户token注销时间的key前缀，TokenRevoked_<userid>，值为注销时间（秒）
+// 签发时间不晚于该时间的token均无效
+const TokenRevoked = "TokenRevoked"
+
+// errTokenRevoked token签发后用户注销了全部session
+var errTokenRevoked = errors.New("the token has been revoked")
+
+// errApiKeyToken 通过API Key认证的请求不签发token
+var errApiKeyToken = errors.New("tokens are not issued to api key requests")
+
+// jwtSigningMethod 签名算法，jwtSignKey/jwtVerifyKey 分别为签名及校验使用的密钥
+var (
+	jwtSigningMethod jwt.SigningMethod
+	jwtSignKey       interface{}
+	jwtVerifyKey     interface{}
+)
+
+//Claims JWT中保存的用户信息，Subject为用户ID
+type Claims struct {
+	UserName    string   `json:"user_name"`
+	Lock        bool     `json:"lock"`
+	Appid       string   `json:"appid"`
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions"`
+	AuthTime    int64    `json:"auth_time"` // 登录时间，刷新token时不会改变
+	State       string   `json:"state,omitempty"`
+	Lang        string   `json:"lang,omitempty"`
+	jwt.RegisteredClaims
+}
+
+//JwtInit 根据配置加载JWT的签名算法及密钥，HS256使用--jwt-secret
+func JwtInit(c *cli.Context) {
+	if !AuthJwtEnabled() {
+		return
+	}
+	switch strings.ToUpper(CONFIG.Auth.Jwt.Algorithm) {
+	case "", "HS256":
+		jwtSigningMethod = jwt.SigningMethodHS256
+		jwtSignKey = []byte(c.String("jwt-secret"))
+		jwtVerifyKey = jwtSignKey
+	case "RS256":
+		jwtSigningMethod = jwt.SigningMethodRS256
+		privateKey, err := loadRSAPrivateKey(CONFIG.Auth.Jwt.PrivateKey)
+		if err != nil {
+			LogFatalf("Load jwt private key failed.", logrus.Fields{"err": err, "path": CONFIG.Auth.Jwt.PrivateKey})
+		}
+		jwtSignKey = privateKey
+		jwtVerifyKey = &privateKey.PublicKey
+		// 单独配置公钥时使用配置的公钥校验
+		if CONFIG.Auth.Jwt.PublicKey != "" {
+			publicKey, err := loadRSAPublicKey(CONFIG.Auth.Jwt.PublicKey)
+			if err != nil {
+				LogFatalf("Load jwt public key failed.", logrus.Fields{"err": err, "path": CONFIG.Auth.Jwt.PublicKey})
+			}
+			jwtVerifyKey = publicKey
+		}
+	default:
+		LogFatalf("Unsupported jwt algorithm.", logrus.Fields{"algorithm": CONFIG.Auth.Jwt.Algorithm})
+	}
+	LogInfof("load jwt config success", logrus.Fields{"mode": CONFIG.Auth.Mode, "algorithm": jwtSigningMethod.Alg()})
+}
+
+//AuthSessionEnabled 是否启用Redis session认证
+func AuthSessionEnabled() bool {
+	mode := strings.ToLower(CONFIG.Auth.Mode)
+	return mode == "" || mode == AuthModeSession || mode == AuthModeBoth
+}
+
+//AuthJwtEnabled 是否启用JWT认证
+func AuthJwtEnabled() bool {
+	mode := strings.ToLower(CONFIG.Auth.Mode)
+	return mode == AuthModeJwt || mode == AuthModeBoth
+}
+
+//CreateToken 根据session信息签发JWT
+func (s *Session) CreateToken() (token string, err error) {
+	// API Key吊销后不能继续使用由其签发的token
+	if s.ApiKey {
+		return "", errApiKeyToken
+	}
+	now := time.Now()
+	if s.CreatedAt == 0 {
+		s.CreatedAt = now.Unix()
+	}
+	expire := now.Add(time.Duration(tokenExpireTime()) * time.Second)
+	// 不超过session的最长存活时间
+	if lifetime := s.Lifetime(); lifetime >= 0 && now.Add(time.Duration(lifetime)*time.Second).Before(expire) {
+		expire = now.Add(time.Duration(lifetime) * time.Second)
+	}
+	claims := Claims{
+		UserName:    s.UserName,
+		Lock:        s.Lock,
+		Appid:       s.Appid,
+		Roles:       s.Roles,
+		Permissions: s.Permissions,
+		AuthTime:    s.CreatedAt,
+		State:       s.State,
+		Lang:        s.Lang,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   s.UserID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expire),
+		},
+	}
+	token, err = jwt.NewWithClaims(jwtSigningMethod, claims).SignedString(jwtSignKey)
+	return
+}
+
+//ParseToken 校验JWT并返回其中的session信息
+// 返回的code为对应的错误类型，格式错误为ErrTokenParse，签名错误、过期或已注销为ErrTokenInvalid
+func ParseToken(token string) (s *Session, expiresAt time.Time, code string, err error) {
+	var claims Claims
+	_, err = jwt.ParseWithClaims(token, &claims, func(t *jwt.Token) (interface{}, error) {
+		if t.Method.Alg() != jwtSigningMethod.Alg() {
+			return nil, errors.New("unexpected signing method " + t.Method.Alg())
+		}
+		return jwtVerifyKey, nil
+	})
+	if err != nil {
+		var validationErr *jwt.ValidationError
+		if errors.As(err, &validationErr) && validationErr.Errors&jwt.ValidationErrorMalformed != 0 {
+			return nil, expiresAt, ErrTokenParse, err
+		}
+		return nil, expiresAt, ErrTokenInvalid, err
+	}
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+	if claims.IssuedAt != nil {
+		var revoked bool
+		if revoked, err = tokenRevoked(claims.Subject, claims.IssuedAt.Time); err != nil {
+			return nil, expiresAt, ErrSession, err
+		} else if revoked {
+			return nil, expiresAt, ErrTokenInvalid, errTokenRevoked
+		}
+	}
+	s = &Session{
+		UserID:      claims.Subject,
+		UserName:    claims.UserName,
+		Lock:        claims.Lock,
+		Appid:       claims.Appid,
+		Roles:       claims.Roles,
+		Permissions: claims.Permissions,
+		CreatedAt:   claims.AuthTime,
+		State:       claims.State,
+		Lang:        claims.Lang,
+	}
+	return
+}
+
+// tokenExpireTime token的有效时间，单位秒
+func tokenExpireTime() int {
+	if CONFIG.Auth.Jwt.ExpireTime > 0 {
+		return CONFIG.Auth.Jwt.ExpireTime
+	}
+	return CONFIG.SessionExpireTime
+}
+
+//RevokeTokens 使用户此前签发的token全部失效，记录保留至这些token过期
+func RevokeTokens(userID string) error {
+	if !AuthJwtEnabled() {
+		return nil
+	}
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	return Store.Set(TokenRevoked+"_"+userID, []byte(now), tokenExpireTime())
+}
+
+// tokenRevoked 判断token是否在用户注销全部session之前签发
+// 签发时间精确到秒，注销的同一秒内签发的token也视为已注销
+func tokenRevoked(userID string, issuedAt time.Time) (bool, error) {
+	value, err := Store.Get(TokenRevoked + "_" + userID)
+	if err == ErrStoreNil {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	revokedAt, err := strconv.ParseInt(string(value), 10, 64)
+	if err != nil {
+		return false, err
+	}
+	return issuedAt.Unix() <= revokedAt, nil
+}
+
+func loadRSAPrivateKey(path string) (*rsa.PrivateKey, error) {
+	pem, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return jwt.ParseRSAPrivateKeyFromPEM(pem)
+}
+
+func loadRSAPublicKey(path string) (*rsa.PublicKey, error) {
+	pem, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return jwt.ParseRSAPublicKeyFromPEM(pem)
+}
@@ -0,0 +1,141 @@
+package common
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/sirupsen/logrus"
+)
+
+// 默认的用户查询条件及组属性，适用于Active Directory
+const (
+	defaultLdapUserFilter = "(sAMAccountName=%s)"
+	defaultLdapGroupAttr  = "memberOf"
+)
+
+var errLdapCredentials = errors.New("the directory rejected the user name or password")
+
+//DirectoryConn 目录服务连接，测试时可替换DialDirectory返回进程内的实现
+type DirectoryConn interface {
+	Bind(username, password string) error
+	Search(req *ldap.SearchRequest) (*ldap.SearchResult, error)
+	Close()
+}
+
+//DialDirectory 连接目录服务，Security为true时使用LDAPS
+var DialDirectory = func(cfg WindowsAdConfig) (DirectoryConn, error) {
+	scheme := "ldap"
+	if cfg.Security {
+		scheme = "ldaps"
+	}
+	return ldap.DialURL(fmt.Sprintf("%s://%s:%d", scheme, cfg.Server, cfg.Port),
+		ldap.DialWithTLSConfig(&tls.Config{ServerName: cfg.Server}))
+}
+
+//DirectoryUser 目录服务中校验通过的用户
+type DirectoryUser struct {
+	DN       string
+	UserName string
+	Groups   []string // 所属组的DN
+}
+
+//LdapEnabled 是否启用域账号登录
+func LdapEnabled() bool {
+	return CONFIG.WindowsAd.Status
+}
+
+//LdapAuthenticate 使用服务账号查询用户DN，再以用户身份绑定校验密码
+// 返回的code为对应的错误类型，密码错误为ErrWindowsADFailed，连接或配置错误为ErrWindowsAdError
+func LdapAuthenticate(ctx context.Context, username, password string) (user *DirectoryUser, code string, err error) {
+	cfg := CONFIG.WindowsAd
+	// 空密码会被目录服务当作匿名绑定而成功
+	if username == "" || password == "" {
+		return nil, ErrWindowsADFailed, errLdapCredentials
+	}
+	conn, err := DialDirectory(cfg)
+	if err != nil {
+		LogErrorCtx(ctx, "connect to the directory failed", logrus.Fields{"err": err, "server": cfg.Server})
+		return nil, ErrWindowsAdError, err
+	}
+	defer conn.Close()
+
+	if cfg.BindDN != "" {
+		if err = conn.Bind(cfg.BindDN, cfg.BindPassword); err != nil {
+			LogErrorCtx(ctx, "bind the directory service account failed", logrus.Fields{"err": err, "bind_dn": cfg.BindDN})
+			return nil, ErrWindowsAdError, err
+		}
+	}
+	filter := cfg.UserFilter
+	if filter == "" {
+		filter = defaultLdapUserFilter
+	}
+	groupAttr := cfg.GroupAttribute
+	if groupAttr == "" {
+		groupAttr = defaultLdapGroupAttr
+	}
+	res, err := conn.Search(ldap.NewSearchRequest(
+		cfg.BaseOn, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, 0, false,
+		fmt.Sprintf(filter, ldap.EscapeFilter(username)),
+		[]string{"dn", groupAttr},
+		nil,
+	))
+	if err != nil {
+		LogErrorCtx(ctx, "search the directory user failed", logrus.Fields{"err": err, "base": cfg.BaseOn})
+		return nil, ErrWindowsAdError, err
+	}
+	if len(res.Entries) != 1 {
+		return nil, ErrWindowsADFailed, errLdapCredentials
+	}
+	entry := res.Entries[0]
+	if err = conn.Bind(entry.DN, password); err != nil {
+		return nil, ErrWindowsADFailed, errLdapCredentials
+	}
+
+	return &DirectoryUser{
+		DN:       entry.DN,
+		UserName: username,
+		Groups:   entry.GetAttributeValues(groupAttr),
+	}, "", nil
+}
+
+//LdapRoles 根据GroupRoles将目录组映射为角色，组可以配置为完整的DN或CN，不区分大小写
+func LdapRoles(groups []string) (roles []string) {
+	seen := make(map[string]bool)
+	add := func(role string) {
+		if role != "" && !seen[role] {
+			seen[role] = true
+			roles = append(roles, role)
+		}
+	}
+	for _, role := range CONFIG.WindowsAd.DefaultRoles {
+		add(role)
+	}
+	for _, group := range groups {
+		for name, role := range CONFIG.WindowsAd.GroupRoles {
+			if strings.EqualFold(name, group) || strings.EqualFold(name, groupCN(group)) {
+				add(role)
+			}
+		}
+	}
+	return
+}
+
+// groupCN 获取组DN中第一个CN的值
+func groupCN(dn string) string {
+	parsed, err := ldap.ParseDN(dn)
+	if err != nil {
+		return ""
+	}
+	for _, rdn := range parsed.RDNs {
+		for _, attr := range rdn.Attributes {
+			if strings.EqualFold(attr.Type, "CN") {
+				return attr.Value
+			}
+		}
+	}
+	return ""
+}
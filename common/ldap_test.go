@@ -0,0 +1,154 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// fakeDirectory 进程内的目录服务，用户按sAMAccountName查询
+type fakeDirectory struct {
+	passwords map[string]string // DN对应的密码
+	entries   []*ldap.Entry
+	closed    bool
+}
+
+func (d *fakeDirectory) Bind(username, password string) error {
+	if pw, ok := d.passwords[username]; !ok || pw != password {
+		return ldap.NewError(ldap.LDAPResultInvalidCredentials, errors.New("invalid credentials"))
+	}
+	return nil
+}
+
+func (d *fakeDirectory) Search(req *ldap.SearchRequest) (*ldap.SearchResult, error) {
+	res := &ldap.SearchResult{}
+	for _, entry := range d.entries {
+		if "(sAMAccountName="+entry.GetAttributeValue("sAMAccountName")+")" == req.Filter {
+			res.Entries = append(res.Entries, entry)
+		}
+	}
+	return res, nil
+}
+
+func (d *fakeDirectory) Close() {
+	d.closed = true
+}
+
+const (
+	testServiceDN = "CN=svc,OU=Service,DC=miku,DC=local"
+	testUserDN    = "CN=Miku,OU=Users,DC=miku,DC=local"
+	testDbaGroup  = "CN=DBA,OU=Groups,DC=miku,DC=local"
+)
+
+// setupDirectory 使用fakeDirectory替换DialDirectory
+func setupDirectory(t *testing.T) *fakeDirectory {
+	t.Helper()
+	CONFIG = &App{WindowsAd: WindowsAdConfig{
+		Status:       true,
+		BaseOn:       "DC=miku,DC=local",
+		BindDN:       testServiceDN,
+		BindPassword: "svc-password",
+	}}
+	dir := &fakeDirectory{
+		passwords: map[string]string{
+			testServiceDN: "svc-password",
+			testUserDN:    "user-password",
+		},
+		entries: []*ldap.Entry{
+			ldap.NewEntry(testUserDN, map[string][]string{
+				"sAMAccountName": {"miku"},
+				"memberOf":       {testDbaGroup},
+			}),
+		},
+	}
+	dial := DialDirectory
+	DialDirectory = func(WindowsAdConfig) (DirectoryConn, error) {
+		return dir, nil
+	}
+	t.Cleanup(func() {
+		DialDirectory = dial
+	})
+	return dir
+}
+
+func TestLdapAuthenticate(t *testing.T) {
+	dir := setupDirectory(t)
+
+	user, code, err := LdapAuthenticate(context.Background(), "miku", "user-password")
+	if err != nil {
+		t.Fatalf("LdapAuthenticate = %s, %v", code, err)
+	}
+	want := &DirectoryUser{DN: testUserDN, UserName: "miku", Groups: []string{testDbaGroup}}
+	if !reflect.DeepEqual(user, want) {
+		t.Fatalf("LdapAuthenticate = %+v, want %+v", user, want)
+	}
+	if !dir.closed {
+		t.Fatal("the directory connection was not closed")
+	}
+}
+
+func TestLdapAuthenticateFailed(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		password string
+		setup    func(dir *fakeDirectory)
+		code     string
+	}{
+		{"wrong password", "miku", "wrong", nil, ErrWindowsADFailed},
+		{"unknown user", "rin", "user-password", nil, ErrWindowsADFailed},
+		{"empty password", "miku", "", nil, ErrWindowsADFailed},
+		{"service account", "miku", "user-password", func(dir *fakeDirectory) {
+			dir.passwords[testServiceDN] = "changed"
+		}, ErrWindowsAdError},
+		{"dial", "miku", "user-password", func(*fakeDirectory) {
+			DialDirectory = func(WindowsAdConfig) (DirectoryConn, error) {
+				return nil, errors.New("connection refused")
+			}
+		}, ErrWindowsAdError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := setupDirectory(t)
+			if tt.setup != nil {
+				tt.setup(dir)
+			}
+			user, code, err := LdapAuthenticate(context.Background(), tt.username, tt.password)
+			if err == nil || user != nil || code != tt.code {
+				t.Fatalf("LdapAuthenticate = %+v, %s, %v, want %s", user, code, err, tt.code)
+			}
+		})
+	}
+}
+
+func TestLdapRoles(t *testing.T) {
+	CONFIG = &App{WindowsAd: WindowsAdConfig{
+		DefaultRoles: []string{"user"},
+		GroupRoles: map[string]string{
+			"cn=dba,ou=groups,dc=miku,dc=local": "dba",
+			"Developers":                        "developer",
+			"ops":                               "user",
+		},
+	}}
+	tests := []struct {
+		name   string
+		groups []string
+		want   []string
+	}{
+		{"default", nil, []string{"user"}},
+		{"dn", []string{testDbaGroup}, []string{"user", "dba"}},
+		{"cn", []string{"CN=developers,OU=Groups,DC=miku,DC=local"}, []string{"user", "developer"}},
+		{"unmapped", []string{"CN=Guests,DC=miku,DC=local", "not a dn"}, []string{"user"}},
+		{"duplicate", []string{"CN=Ops,DC=miku,DC=local"}, []string{"user"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LdapRoles(tt.groups); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("LdapRoles(%v) = %v, want %v", tt.groups, got, tt.want)
+			}
+		})
+	}
+}
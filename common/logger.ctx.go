@@ -0,0 +1,117 @@
+package common
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/satori/go.uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// loggerKey is the gin context key of the request logger.
+const loggerKey = "logger"
+
+// loggerCtxKey is the context.Context key of the request logger.
+type loggerCtxKey struct{}
+
+// requestIdPattern limits the incoming X-Request-Id to safe characters.
+var requestIdPattern = regexp.MustCompile(`^[A-Za-z0-9._:+/=-]{1,128}$`)
+
+//Logger 请求日志，携带的字段（request_id、user_id、route）会附加到每条日志
+type Logger struct {
+	fields logrus.Fields
+}
+
+//With 返回附加了字段的新Logger
+func (l *Logger) With(fds logrus.Fields) *Logger {
+	res := &Logger{fields: make(logrus.Fields, len(l.fields)+len(fds))}
+	for k, v := range l.fields {
+		res.fields[k] = v
+	}
+	for k, v := range fds {
+		res.fields[k] = v
+	}
+	return res
+}
+
+//Fields 返回Logger携带的字段
+func (l *Logger) Fields() logrus.Fields {
+	return l.With(nil).fields
+}
+
+//LoggerFrom 返回ctx中的请求日志，gin.Context及其Request的context均可，没有时返回空的Logger
+func LoggerFrom(ctx context.Context) *Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(loggerKey).(*Logger); ok {
+			return l
+		}
+		if l, ok := ctx.Value(loggerCtxKey{}).(*Logger); ok {
+			return l
+		}
+	}
+	return &Logger{}
+}
+
+//WithLogFields 为当前请求的日志附加字段
+func WithLogFields(c *gin.Context, fds logrus.Fields) {
+	setLogger(c, LoggerFrom(c).With(fds))
+}
+
+// setLogger attaches the logger to the gin context and to the request context.
+func setLogger(c *gin.Context, l *Logger) {
+	c.Set(loggerKey, l)
+	c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), loggerCtxKey{}, l))
+}
+
+// RequestId is a middleware function that appends the id of request, an incoming
+// X-Request-Id is kept so the id carries over from upstream proxies.
+func RequestId(c *gin.Context) {
+	id := c.GetHeader("X-Request-Id")
+	if !requestIdPattern.MatchString(id) {
+		id = uuid.NewV4().String()
+	}
+	c.Set("request-id", id)
+	c.Header("X-Request-Id", id)
+	setLogger(c, &Logger{fields: logrus.Fields{
+		"request_id": id,
+		"route":      c.FullPath(),
+	}})
+	c.Next()
+}
+
+//RequestIdFrom 返回ctx中请求的request_id
+func RequestIdFrom(ctx context.Context) string {
+	id, _ := LoggerFrom(ctx).fields["request_id"].(string)
+	return id
+}
+
+func LogDebugCtx(ctx context.Context, msg string, fds logrus.Fields) {
+	ctxFields(ctx, "Debug", msg, fds)
+}
+func LogTraceCtx(ctx context.Context, msg string, fds logrus.Fields) {
+	ctxFields(ctx, "Trace", msg, fds)
+}
+func LogInfoCtx(ctx context.Context, msg string, fds logrus.Fields) {
+	ctxFields(ctx, "Info", msg, fds)
+}
+func LogWarnCtx(ctx context.Context, msg string, fds logrus.Fields) {
+	ctxFields(ctx, "Warn", msg, fds)
+}
+func LogErrorCtx(ctx context.Context, msg string, fds logrus.Fields) {
+	ctxFields(ctx, "Error", msg, fds)
+}
+
+// ctxFields logs with the fields of the request logger, using the level override of its route.
+func ctxFields(ctx context.Context, lvl string, msg string, fds logrus.Fields) {
+	l := LoggerFrom(ctx)
+	level := LogLevel()
+	if route, ok := l.fields["route"].(string); ok {
+		if override, ok := routeLogLevel(route); ok {
+			level = override
+		}
+	}
+	if logEnabled(lvl, level) {
+		writeFields(lvl, msg, l.With(fds).fields)
+	}
+}
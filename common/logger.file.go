@@ -0,0 +1,269 @@
+package common
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 日志文件的切割方式
+const (
+	LogRotateSize  = "size"  // 超过MaxSize时切割
+	LogRotateDaily = "daily" // 每天切割，超过MaxSize时也会切割
+)
+
+// 日志文件名称，std及stdErr分别写入不同文件
+const (
+	logFileInfo  = "info.log"  // debug、trace、info、warn
+	logFileError = "error.log" // error、fatal
+)
+
+//logFiles 当前写入的日志文件
+var logFiles []*rotateFile
+
+//logFileCheckInterval 检查日志文件是否被外部logrotate移动或清空的间隔
+// endless收到SIGHUP时会fork新进程，因此不使用信号通知重新打开
+var logFileCheckInterval = time.Second
+
+// rotateFile is a log file rotated by size or by day, rotated files are renamed with
+// a timestamp suffix, then compressed and cleaned in the background.
+type rotateFile struct {
+	mu     sync.Mutex
+	path   string
+	conf   LogConfig
+	file   *os.File
+	size   int64
+	opened time.Time
+	check  time.Time // 上次检查文件是否被移动的时间
+	clean  sync.Mutex
+}
+
+func openRotateFile(path string, conf LogConfig) (*rotateFile, error) {
+	f := &rotateFile{path: path, conf: conf}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// open opens the file in append mode, the caller holds the lock.
+func (f *rotateFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file = file
+	f.size = info.Size()
+	f.opened = info.ModTime()
+	if f.size == 0 {
+		f.opened = time.Now()
+	}
+	return nil
+}
+
+// Write writes the log line, rotating the file first when it is full or from another day.
+func (f *rotateFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+	if now := time.Now(); now.Sub(f.check) >= logFileCheckInterval {
+		f.check = now
+		if err := f.checkMoved(); err != nil {
+			fmt.Fprintf(os.Stderr, "reopen log file %s failed: %v\n", f.path, err)
+		}
+	}
+	if f.shouldRotate(len(p)) {
+		if err := f.rotate(); err != nil {
+			fmt.Fprintf(os.Stderr, "rotate log file %s failed: %v\n", f.path, err)
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *rotateFile) shouldRotate(n int) bool {
+	if f.size == 0 {
+		return false
+	}
+	if f.conf.MaxSize > 0 && f.size+int64(n) > int64(f.conf.MaxSize)*1024*1024 {
+		return true
+	}
+	if strings.ToLower(f.conf.Rotate) == LogRotateDaily {
+		y1, m1, d1 := f.opened.Date()
+		y2, m2, d2 := time.Now().Date()
+		return y1 != y2 || m1 != m2 || d1 != d2
+	}
+	return false
+}
+
+// rotate renames the current file and opens a new one, the caller holds the lock.
+func (f *rotateFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+	rotated := f.path + "." + time.Now().Format("20060102-150405.000")
+	if err := os.Rename(f.path, rotated); err != nil {
+		return err
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+	go f.cleanup(rotated)
+	return nil
+}
+
+// checkMoved reopens the file when an external logrotate has moved or removed it, and
+// follows a copytruncate, the caller holds the lock.
+func (f *rotateFile) checkMoved() error {
+	current, err := f.file.Stat()
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(f.path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err == nil && os.SameFile(info, current) {
+		// copytruncate清空了文件，O_APPEND模式下继续从文件开头写入
+		if current.Size() < f.size {
+			f.size = current.Size()
+			f.opened = time.Now()
+		}
+		return nil
+	}
+	return f.reopen()
+}
+
+// Reopen closes and opens the file again, after it has been moved by an external logrotate.
+func (f *rotateFile) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.reopen()
+}
+
+func (f *rotateFile) reopen() error {
+	if f.file != nil {
+		f.file.Close()
+		f.file = nil
+	}
+	return f.open()
+}
+
+// cleanup compresses the rotated file and removes the backups beyond MaxBackups or MaxAge.
+func (f *rotateFile) cleanup(rotated string) {
+	f.clean.Lock()
+	defer f.clean.Unlock()
+
+	if f.conf.Compress {
+		if err := gzipFile(rotated); err != nil {
+			fmt.Fprintf(os.Stderr, "compress log file %s failed: %v\n", rotated, err)
+		}
+	}
+
+	backups, err := filepath.Glob(f.path + ".*")
+	if err != nil {
+		return
+	}
+	// 文件名中的时间戳保证按名称排序即为按时间排序，最新的在前
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+	deadline := time.Now().AddDate(0, 0, -f.conf.MaxAge)
+	for i, path := range backups {
+		remove := f.conf.MaxBackups > 0 && i >= f.conf.MaxBackups
+		if !remove && f.conf.MaxAge > 0 {
+			if info, err := os.Stat(path); err == nil && info.ModTime().Before(deadline) {
+				remove = true
+			}
+		}
+		if remove {
+			_ = os.Remove(path)
+		}
+	}
+}
+
+// gzipFile replaces the file with its gzip compressed copy.
+func gzipFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	if _, err = io.Copy(zw, src); err == nil {
+		err = zw.Close()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path + ".gz")
+		return err
+	}
+	return os.Remove(path)
+}
+
+//LogFileInit 根据配置将日志写入文件，需在ConfigParse之后调用
+// 日志文件被外部logrotate移动或清空后，下次写入时自动重新打开，logrotate的postrotate不能发送SIGHUP
+func LogFileInit() {
+	conf := CONFIG.Log
+	if conf.Dir == "" {
+		return
+	}
+	if err := os.MkdirAll(conf.Dir, 0755); err != nil {
+		LogFatalf("Create log dir failed.", logrus.Fields{"err": err, "dir": conf.Dir})
+	}
+	infoFile, err := openRotateFile(filepath.Join(conf.Dir, logFileInfo), conf)
+	if err != nil {
+		LogFatalf("Open log file failed.", logrus.Fields{"err": err, "dir": conf.Dir})
+	}
+	errorFile, err := openRotateFile(filepath.Join(conf.Dir, logFileError), conf)
+	if err != nil {
+		LogFatalf("Open log file failed.", logrus.Fields{"err": err, "dir": conf.Dir})
+	}
+	logFiles = []*rotateFile{infoFile, errorFile}
+
+	var stdOut, stdErrOut io.Writer = infoFile, errorFile
+	if conf.Stdout {
+		stdOut = io.MultiWriter(os.Stdout, infoFile)
+		stdErrOut = io.MultiWriter(os.Stderr, errorFile)
+	}
+	std.SetOutput(stdOut)
+	std.Formatter = newFormatter(logFormat, stdOut)
+	stdErr.SetOutput(stdErrOut)
+	stdErr.Formatter = newFormatter(logFormat, stdErrOut)
+
+	LogInfof("write logs to files", logrus.Fields{"dir": conf.Dir, "rotate": conf.Rotate})
+}
+
+//LogReopen 立即重新打开日志文件，未写入文件时不做处理
+func LogReopen() error {
+	for _, f := range logFiles {
+		if err := f.Reopen(); err != nil {
+			return fmt.Errorf("reopen log file %s: %w", f.path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setupLogFile 打开临时目录中的日志文件，每次写入都检查文件是否被移动
+func setupLogFile(t *testing.T) (*rotateFile, string) {
+	t.Helper()
+	interval := logFileCheckInterval
+	logFileCheckInterval = 0
+	path := filepath.Join(t.TempDir(), logFileInfo)
+	f, err := openRotateFile(path, LogConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		logFileCheckInterval = interval
+		f.file.Close()
+	})
+	return f, path
+}
+
+func writeLog(t *testing.T, f *rotateFile, line string) {
+	t.Helper()
+	if _, err := f.Write([]byte(line)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func assertFile(t *testing.T, path, want string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != want {
+		t.Fatalf("%s = %q, want %q", filepath.Base(path), data, want)
+	}
+}
+
+func TestRotateFileMoved(t *testing.T) {
+	f, path := setupLogFile(t)
+	writeLog(t, f, "first\n")
+
+	// logrotate的create模式：移动文件后由服务重新创建
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	writeLog(t, f, "second\n")
+	assertFile(t, path+".1", "first\n")
+	assertFile(t, path, "second\n")
+}
+
+func TestRotateFileTruncated(t *testing.T) {
+	f, path := setupLogFile(t)
+	writeLog(t, f, "first\n")
+
+	// logrotate的copytruncate模式：复制后清空原文件
+	if err := os.Truncate(path, 0); err != nil {
+		t.Fatal(err)
+	}
+	writeLog(t, f, "second\n")
+	assertFile(t, path, "second\n")
+	if f.size != int64(len("second\n")) {
+		t.Fatalf("size = %d after the truncate", f.size)
+	}
+}
@@ -0,0 +1,213 @@
+package common
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// logLevel is the global level, changed at runtime by SetLogLevel and the signals.
+var logLevel = uint32(logrus.DebugLevel)
+
+// baseLevel is the level set without a duration, temporary levels revert to it. It is
+// guarded by levelMu.
+var baseLevel = logrus.DebugLevel
+
+// helperLevels maps the level names used by the Log* helpers to logrus levels.
+var helperLevels = map[string]logrus.Level{
+	"Fatal": logrus.FatalLevel,
+	"Error": logrus.ErrorLevel,
+	"Warn":  logrus.WarnLevel,
+	"Info":  logrus.InfoLevel,
+	"Debug": logrus.DebugLevel,
+	"Trace": logrus.TraceLevel,
+}
+
+// levelMu guards the base level, the revert timer of the global level and the route overrides.
+var levelMu sync.Mutex
+var levelTimer *time.Timer
+
+//RouteLevel 路由的日志级别，到期后恢复使用全局级别
+type RouteLevel struct {
+	Route     string     `json:"route"`
+	Level     string     `json:"level"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	timer     *time.Timer
+}
+
+// routeLevels holds the overrides by route pattern, such as /v1/auth/login.
+var routeLevels = make(map[string]*RouteLevel)
+
+//ParseLogLevel 解析日志级别名称，FATAL、ERROR、WARN、INFO、DEBUG、TRACE，不区分大小写
+func ParseLogLevel(name string) (logrus.Level, error) {
+	switch strings.ToUpper(name) {
+	case "FATAL":
+		return logrus.FatalLevel, nil
+	case "ERROR":
+		return logrus.ErrorLevel, nil
+	case "WARN", "WARNING":
+		return logrus.WarnLevel, nil
+	case "INFO":
+		return logrus.InfoLevel, nil
+	case "DEBUG":
+		return logrus.DebugLevel, nil
+	case "TRACE":
+		return logrus.TraceLevel, nil
+	}
+	return logrus.DebugLevel, fmt.Errorf("unknown log level `%s`", name)
+}
+
+//LogLevelName 日志级别的名称，与日志中输出的一致，如 WARN
+func LogLevelName(level logrus.Level) string {
+	return logLevelName(level)
+}
+
+//LogLevel 当前的全局日志级别
+func LogLevel() logrus.Level {
+	return logrus.Level(atomic.LoadUint32(&logLevel))
+}
+
+//SetLogLevel 设置全局日志级别，同时作为临时级别到期后恢复的级别，取消尚未到期的恢复
+func SetLogLevel(level logrus.Level) {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+	stopLevelTimer()
+	baseLevel = level
+	atomic.StoreUint32(&logLevel, uint32(level))
+}
+
+//SetLogLevelFor 临时设置全局日志级别，duration后恢复为SetLogLevel设置的级别，duration为0时同SetLogLevel
+// 多次临时修改不会改变恢复的级别
+func SetLogLevelFor(level logrus.Level, duration time.Duration) {
+	if duration <= 0 {
+		SetLogLevel(level)
+		return
+	}
+	levelMu.Lock()
+	defer levelMu.Unlock()
+	stopLevelTimer()
+	atomic.StoreUint32(&logLevel, uint32(level))
+	var timer *time.Timer
+	timer = time.AfterFunc(duration, func() {
+		levelMu.Lock()
+		// 期间再次修改过级别时不恢复
+		if levelTimer != timer {
+			levelMu.Unlock()
+			return
+		}
+		levelTimer = nil
+		base := baseLevel
+		atomic.StoreUint32(&logLevel, uint32(base))
+		levelMu.Unlock()
+		LogInfof("log level reverted", logrus.Fields{"level": logLevelName(base)})
+	})
+	levelTimer = timer
+}
+
+// stopLevelTimer cancels the pending revert, the caller holds levelMu.
+func stopLevelTimer() {
+	if levelTimer != nil {
+		levelTimer.Stop()
+		levelTimer = nil
+	}
+}
+
+//SetRouteLogLevel 设置路由的日志级别，duration后恢复使用全局级别，duration为0时不恢复
+func SetRouteLogLevel(route string, level logrus.Level, duration time.Duration) {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+	if old, ok := routeLevels[route]; ok && old.timer != nil {
+		old.timer.Stop()
+	}
+	rl := &RouteLevel{Route: route, Level: logLevelName(level)}
+	if duration > 0 {
+		expiresAt := time.Now().Add(duration)
+		rl.ExpiresAt = &expiresAt
+		rl.timer = time.AfterFunc(duration, func() {
+			levelMu.Lock()
+			if routeLevels[route] != rl {
+				levelMu.Unlock()
+				return
+			}
+			delete(routeLevels, route)
+			levelMu.Unlock()
+			LogInfof("route log level reverted", logrus.Fields{"route": route})
+		})
+	}
+	routeLevels[route] = rl
+}
+
+//DeleteRouteLogLevel 删除路由的日志级别，返回是否存在
+func DeleteRouteLogLevel(route string) bool {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+	rl, ok := routeLevels[route]
+	if ok {
+		if rl.timer != nil {
+			rl.timer.Stop()
+		}
+		delete(routeLevels, route)
+	}
+	return ok
+}
+
+//RouteLogLevels 全部路由的日志级别，按路由排序
+func RouteLogLevels() []RouteLevel {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+	res := make([]RouteLevel, 0, len(routeLevels))
+	for _, rl := range routeLevels {
+		res = append(res, *rl)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Route < res[j].Route
+	})
+	return res
+}
+
+// routeLogLevel returns the override of the route.
+func routeLogLevel(route string) (logrus.Level, bool) {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+	rl, ok := routeLevels[route]
+	if !ok {
+		return 0, false
+	}
+	level, _ := ParseLogLevel(rl.Level)
+	return level, true
+}
+
+// logEnabled reports whether a log of the helper level is written at the level, fatal always is.
+func logEnabled(lvl string, level logrus.Level) bool {
+	l, ok := helperLevels[lvl]
+	return !ok || l == logrus.FatalLevel || l <= level
+}
+
+// logSignals steps the global level with SIGUSR1 (more verbose) and SIGUSR2 (less verbose),
+// between ERROR and TRACE.
+func logSignals() {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGUSR1, syscall.SIGUSR2)
+	go func() {
+		for sig := range ch {
+			level := LogLevel()
+			if sig == syscall.SIGUSR1 && level < logrus.TraceLevel {
+				level++
+			} else if sig == syscall.SIGUSR2 && level > logrus.ErrorLevel {
+				level--
+			} else {
+				continue
+			}
+			SetLogLevel(level)
+			LogWarnf("log level changed by signal", logrus.Fields{"signal": sig.String(), "level": logLevelName(level)})
+		}
+	}()
+}
@@ -0,0 +1,40 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func waitLogLevel(t *testing.T, want logrus.Level) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for LogLevel() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("LogLevel = %s, want %s", logLevelName(LogLevel()), logLevelName(want))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestSetLogLevelFor(t *testing.T) {
+	SetLogLevel(logrus.InfoLevel)
+	t.Cleanup(func() {
+		SetLogLevel(logrus.DebugLevel)
+	})
+
+	// 连续的临时修改恢复为基础级别，而不是上一个临时级别
+	SetLogLevelFor(logrus.DebugLevel, time.Hour)
+	SetLogLevelFor(logrus.TraceLevel, 20*time.Millisecond)
+	if LogLevel() != logrus.TraceLevel {
+		t.Fatalf("LogLevel = %s, want TRACE", logLevelName(LogLevel()))
+	}
+	waitLogLevel(t, logrus.InfoLevel)
+
+	// 临时修改期间设置的基础级别在到期后生效
+	SetLogLevelFor(logrus.TraceLevel, 20*time.Millisecond)
+	SetLogLevel(logrus.WarnLevel)
+	SetLogLevelFor(logrus.DebugLevel, 20*time.Millisecond)
+	waitLogLevel(t, logrus.WarnLevel)
+}
@@ -1,67 +1,102 @@
 package common
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"runtime"
+	"sort"
 	"strings"
 
 	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
 	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v2"
 )
 
+// 日志输出格式
+const (
+	LogFormatText = "text" // [name] LEVEL time reqid msg (k=v …)
+	LogFormatJson = "json" // 每行一个JSON对象，供日志采集使用
+)
+
+// logServiceName is the service name written in every log line.
+const logServiceName = "Go-Web-Api"
+
 // std output to stdout, stdErr output to stderr.
 var std = logrus.New()
 var stdErr = logrus.New()
 
+// logFormat is the output format chosen by --log-format.
+var logFormat = LogFormatText
+
+// levelColors is the color of each level in the text format.
+var levelColors = map[string]*color.Color{
+	"DEBUG": color.New(color.FgWhite),
+	"TRACE": color.New(color.FgWhite),
+	"INFO":  color.New(color.FgCyan),
+	"WARN":  color.New(color.FgYellow),
+	"ERROR": color.New(color.FgRed),
+	"FATAL": color.New(color.FgHiRed),
+}
+
+func init() {
+	// 是否输出颜色由formatter根据输出是否为终端决定
+	for _, c := range levelColors {
+		c.EnableColor()
+	}
+}
+
 // formatter formats the output format.
 type formatter struct {
-	isStdout    bool
+	color       bool // colour the level, only when the output is a terminal
 	serviceName string
 }
 
 // Format the input log.
 func (f *formatter) Format(e *logrus.Entry) ([]byte, error) {
-	// Implode the data to string with `k=v` format.
+	// Implode the data to string with `k=v` format, sorted by key.
+	// The request id of request loggers is printed after the time.
+	requestId, _ := e.Data["request_id"].(string)
 	dataString := ""
 	if len(e.Data) != 0 {
-		for k, v := range e.Data {
-			dataString += fmt.Sprintf("%s=%+v ", k, v)
+		keys := make([]string, 0, len(e.Data))
+		for k := range e.Data {
+			if k != "request_id" {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			dataString += fmt.Sprintf("%s=%+v ", k, e.Data[k])
 		}
 		// Trim the trailing whitespace.
-		dataString = dataString[0 : len(dataString)-1]
+		dataString = strings.TrimSuffix(dataString, " ")
 	}
 	// Get service name.
 	name := f.serviceName
 	// Level like: DEBUG, INFO, WARN, ERROR, FATAL.
-	level := strings.ToUpper(e.Level.String())
+	level := logLevelName(e.Level)
 	// Get the time with YYYY-mm-dd H:i:s format.
 	time := e.Time.Format("2006-01-02 15:04:05")
 	// Get the message.
 	msg := e.Message
 
-	// Set the color of the level with STDOUT.
-	stdLevel := ""
-	switch level {
-	case "DEBUG":
-		stdLevel = color.New(color.FgWhite).Sprint(level)
-	case "TRACE":
-		stdLevel = color.New(color.FgWhite).Sprint(level)
-	case "INFO":
-		stdLevel = color.New(color.FgCyan).Sprint(" " + level)
-	case "WARN":
-		stdLevel = color.New(color.FgYellow).Sprint(" " + level)
-	case "ERROR":
-		stdLevel = color.New(color.FgRed).Sprint(level)
-	case "FATAL":
-		stdLevel = color.New(color.FgHiRed).Sprint(level)
-	}
-
-	body := fmt.Sprintf("[%s] %5s %s %s %s", name, stdLevel, time, REQUEST_ID, msg)
+	// Set the color of the level when the output is a terminal.
+	stdLevel := level
+	if len(level) == 4 {
+		stdLevel = " " + level
+	}
+	if c, ok := levelColors[level]; ok && f.color {
+		stdLevel = c.Sprint(stdLevel)
+	}
+
+	body := fmt.Sprintf("[%s] %5s %s %s %s", name, stdLevel, time, requestId, msg)
 	data := fmt.Sprintf(" (%s)", dataString)
 
 	// Hide the data if there's no data.
-	if len(e.Data) == 0 {
+	if dataString == "" {
 		data = ""
 	}
 
@@ -71,46 +106,131 @@ func (f *formatter) Format(e *logrus.Entry) ([]byte, error) {
 	return []byte(output), nil
 }
 
+// jsonFormatter formats the log as one json object per line with stable keys.
+type jsonFormatter struct {
+	serviceName string
+}
+
+// jsonEntry is a line of the json format, the keys of fields are sorted by encoding/json.
+type jsonEntry struct {
+	Ts        string                 `json:"ts"`
+	Level     string                 `json:"level"`
+	Service   string                 `json:"service"`
+	RequestID string                 `json:"request_id"`
+	Msg       string                 `json:"msg"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
+	Caller    string                 `json:"caller,omitempty"`
+}
+
+// Format the input log.
+func (f *jsonFormatter) Format(e *logrus.Entry) ([]byte, error) {
+	entry := jsonEntry{
+		Ts:      e.Time.Format("2006-01-02T15:04:05.000Z07:00"),
+		Level:   strings.ToLower(logLevelName(e.Level)),
+		Service: f.serviceName,
+		Msg:     e.Message,
+		Caller:  logCaller(),
+	}
+	entry.RequestID, _ = e.Data["request_id"].(string)
+	if len(e.Data) != 0 {
+		entry.Fields = make(map[string]interface{}, len(e.Data))
+		for k, v := range e.Data {
+			if k == "request_id" {
+				continue
+			}
+			// error没有导出字段，json编码为{}
+			if err, ok := v.(error); ok {
+				v = err.Error()
+			}
+			entry.Fields[k] = v
+		}
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		// 无法编码的字段使用文本格式
+		for k, v := range entry.Fields {
+			entry.Fields[k] = fmt.Sprintf("%+v", v)
+		}
+		if data, err = json.Marshal(entry); err != nil {
+			return nil, err
+		}
+	}
+	return append(data, '\n'), nil
+}
+
+// logLevelName returns the upper case level, WARN for the warning level.
+func logLevelName(level logrus.Level) string {
+	if level == logrus.WarnLevel {
+		return "WARN"
+	}
+	return strings.ToUpper(level.String())
+}
+
+// logCaller returns the file:line that called the Log* helpers, skipping logrus and this file.
+func logCaller() string {
+	pcs := make([]uintptr, 16)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.Contains(frame.Function, "sirupsen/logrus") && !strings.HasSuffix(frame.File, "/logger.std.go") &&
+			!strings.HasSuffix(frame.File, "/logger.ctx.go") {
+			return fmt.Sprintf("%s:%d", strings.Replace(frame.File, os.Getenv("GOPATH")+"/src/", "", -1), frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// newFormatter creates the formatter of the output, colours are only used on a terminal.
+func newFormatter(format string, out io.Writer) logrus.Formatter {
+	if format == LogFormatJson {
+		return &jsonFormatter{serviceName: logServiceName}
+	}
+	tty := false
+	if fd, ok := out.(interface{ Fd() uintptr }); ok {
+		tty = isatty.IsTerminal(fd.Fd()) || isatty.IsCygwinTerminal(fd.Fd())
+	}
+	return &formatter{
+		color:       tty && os.Getenv("TERM") != "dumb",
+		serviceName: logServiceName,
+	}
+}
+
 // LogInit Init initializes the global logger.
 func LogInit(c *cli.Context) {
-	var stdFmt logrus.Formatter
-
-	// Create the formatter for stdout output.
-	stdFmt = &formatter{
-		isStdout:    false,
-		serviceName: "Go-Web-Api",
+	format := strings.ToLower(c.String("log-format"))
+	logFormat = format
+	if format != LogFormatJson {
+		logFormat = LogFormatText
 	}
 
 	// Std logger.
 	std.Out = os.Stdout
-	std.Formatter = stdFmt
+	std.Formatter = newFormatter(logFormat, std.Out)
 
 	// StdErr logger
-	stdErr.Formatter = stdFmt
-
-	switch strings.ToUpper(c.String("log-level")) {
-	case "FATAL":
-		std.Level = logrus.FatalLevel
-		stdErr.Level = logrus.FatalLevel
-	case "ERROR":
-		std.Level = logrus.ErrorLevel
-		stdErr.Level = logrus.ErrorLevel
-	case "WARN":
-		std.Level = logrus.WarnLevel
-		stdErr.Level = logrus.WarnLevel
-	case "INFO":
-		std.Level = logrus.InfoLevel
-		stdErr.Level = logrus.InfoLevel
-	case "DEBUG":
-		std.Level = logrus.DebugLevel
-		stdErr.Level = logrus.DebugLevel
-	case "TRACE":
-		std.Level = logrus.TraceLevel
-		stdErr.Level = logrus.TraceLevel
-	default:
-		std.Level = logrus.DebugLevel
-		stdErr.Level = logrus.DebugLevel
+	stdErr.Out = os.Stderr
+	stdErr.Formatter = newFormatter(logFormat, stdErr.Out)
+
+	// 输出初始化后再退出，错误以text格式输出
+	if format != logFormat {
+		LogFatalf("Unknown log format.", logrus.Fields{
+			"format":    c.String("log-format"),
+			"supported": []string{LogFormatText, LogFormatJson},
+		})
 	}
+
+	// The level is checked by the Log* helpers, so it can be changed at runtime.
+	std.SetLevel(logrus.TraceLevel)
+	stdErr.SetLevel(logrus.TraceLevel)
+	level, err := ParseLogLevel(c.String("log-level"))
+	if err != nil {
+		level = logrus.DebugLevel
+	}
+	SetLogLevel(level)
+	logSignals()
 }
 
 func LogDebug(msg interface{}) {
@@ -152,6 +272,12 @@ func LogFatalf(msg string, fds logrus.Fields) {
 }
 
 func fields(lvl string, msg string, fds logrus.Fields) {
+	if logEnabled(lvl, LogLevel()) {
+		writeFields(lvl, msg, fds)
+	}
+}
+
+func writeFields(lvl string, msg string, fds logrus.Fields) {
 	s := std.WithFields(fds)
 	sr := stdErr.WithFields(fds)
 
@@ -172,6 +298,9 @@ func fields(lvl string, msg string, fds logrus.Fields) {
 }
 
 func message(lvl string, msg interface{}) {
+	if !logEnabled(lvl, LogLevel()) {
+		return
+	}
 	switch lvl {
 	case "Debug":
 		std.Debug(msg)
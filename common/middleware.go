@@ -59,6 +59,14 @@ func open(driver, config string, serviceName string, values ...interface{}) (db
 			"err": err,
 		})
 	}
+	// 自动迁移传入的数据表模型
+	if len(values) > 0 {
+		if err = db.AutoMigrate(values...); err != nil {
+			LogFatalf("Database migration failed.", logrus.Fields{
+				"err": err,
+			})
+		}
+	}
 
 	return
 }
@@ -102,6 +110,11 @@ func DatabaseConnect(project string, values ...interface{}) gin.HandlerFunc {
 	}
 }
 
+// GetDB returns the database handle attached by DatabaseConnect.
+func GetDB(c *gin.Context) *gorm.DB {
+	return c.MustGet("DB").(*gorm.DB)
+}
+
 // MiddleLogging Logging is a middleware function that logs the each request.
 func MiddleLogging() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -177,10 +190,10 @@ func MiddleLogging() gin.HandlerFunc {
 		msg := fmt.Sprintf(" %s | %13s | %12s | %s %s", statusString, latency, ip, pad.Right(method, 5, " "), path)
 		if len(c.Errors) == 0 {
 			// Example: ● 200 |  102.268592ms |    127.0.0.1 | POST  /user (user_agent=xxx)
-			LogInfof(msg, fields)
+			LogInfoCtx(c, msg, fields)
 		} else {
 			// Example: ▲ 403 |  102.268592ms |    127.0.0.1 | POST  /user (user_agent=xxx error_0=xxx)
-			LogErrorf(msg, fields)
+			LogErrorCtx(c, msg, fields)
 		}
 	}
 }
@@ -0,0 +1,224 @@
+package common
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 分页参数的默认值
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 200
+)
+
+//PageOptions 接口允许的排序及过滤字段，key为请求参数中的字段名，value为数据库列名
+type PageOptions struct {
+	Sorts       map[string]string
+	Filters     map[string]string
+	DefaultSort string // 未指定sort时的排序，如 -id
+	Cursor      string // 游标分页使用的字段，需为Sorts中唯一且有序的字段，为空时不支持游标分页
+}
+
+//Pager 解析后的分页、排序及过滤参数
+/*
+	page=2&page_size=20&sort=-created_at,name&filter[status]=1,2
+	使用游标分页时传入cursor参数，首页为空值：cursor=&page_size=20，之后使用返回的next_cursor
+*/
+type Pager struct {
+	Page     int
+	PageSize int
+	Orders   []string            // 如 created_at DESC
+	Filters  map[string][]string // 列名 -> 可选值，多个值为IN查询
+	Cursor   *pageCursor         // 不为nil时使用游标分页
+}
+
+// pageCursor is the position of a cursor page, the value of the cursor column of the last row.
+type pageCursor struct {
+	column string
+	desc   bool
+	value  interface{}
+}
+
+// pageError returns a validation error for a query parameter.
+func pageError(format string, args ...interface{}) error {
+	return &AppError{Code: ErrValidation, Cause: fmt.Errorf(format, args...), stack: callers(1)}
+}
+
+//ParsePage 读取请求中的page、page_size、sort、filter[field]及cursor参数，不在opts中的字段返回ErrValidation
+func ParsePage(c *gin.Context, opts PageOptions) (*Pager, error) {
+	p := &Pager{Page: 1, PageSize: DefaultPageSize, Filters: make(map[string][]string)}
+	var err error
+	if v := c.Query("page"); v != "" {
+		if p.Page, err = strconv.Atoi(v); err != nil || p.Page < 1 {
+			return nil, pageError("invalid page `%s`", v)
+		}
+	}
+	if v := c.Query("page_size"); v != "" {
+		if p.PageSize, err = strconv.Atoi(v); err != nil || p.PageSize < 1 || p.PageSize > MaxPageSize {
+			return nil, pageError("page_size must be between 1 and %d", MaxPageSize)
+		}
+	}
+
+	sort := c.DefaultQuery("sort", opts.DefaultSort)
+	for _, field := range strings.Split(sort, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		desc := strings.HasPrefix(field, "-")
+		name := strings.TrimLeft(field, "+-")
+		column, ok := opts.Sorts[name]
+		if !ok {
+			return nil, pageError("can not sort by `%s`", name)
+		}
+		if desc {
+			p.Orders = append(p.Orders, column+" DESC")
+		} else {
+			p.Orders = append(p.Orders, column+" ASC")
+		}
+	}
+
+	for name, column := range opts.Filters {
+		if v, ok := c.GetQuery("filter[" + name + "]"); ok {
+			p.Filters[column] = strings.Split(v, ",")
+		}
+	}
+	// 拒绝未开放的过滤字段，避免客户端误以为已生效
+	for key := range c.Request.URL.Query() {
+		if strings.HasPrefix(key, "filter[") && strings.HasSuffix(key, "]") {
+			if _, ok := opts.Filters[key[7:len(key)-1]]; !ok {
+				return nil, pageError("can not filter by `%s`", key[7:len(key)-1])
+			}
+		}
+	}
+
+	if v, ok := c.GetQuery("cursor"); ok {
+		if opts.Cursor == "" {
+			return nil, pageError("cursor pagination is not supported")
+		}
+		if p.Cursor, err = parseCursor(v, opts, sort); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// parseCursor decodes the cursor parameter, the order must only use the cursor field.
+func parseCursor(v string, opts PageOptions, sort string) (*pageCursor, error) {
+	cur := &pageCursor{column: opts.Sorts[opts.Cursor]}
+	if cur.column == "" {
+		cur.column = opts.Cursor
+	}
+	switch strings.TrimSpace(sort) {
+	case "", opts.Cursor, "+" + opts.Cursor:
+	case "-" + opts.Cursor:
+		cur.desc = true
+	default:
+		return nil, pageError("cursor pagination can only sort by `%s`", opts.Cursor)
+	}
+	if v == "" {
+		return cur, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(v)
+	if err != nil {
+		return nil, pageError("invalid cursor")
+	}
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.UseNumber()
+	if err = dec.Decode(&cur.value); err != nil {
+		return nil, pageError("invalid cursor")
+	}
+	return cur, nil
+}
+
+//Scope 将过滤、排序及分页条件应用到查询，用于 db.Scopes(p.Scope)
+func (p *Pager) Scope(db *gorm.DB) *gorm.DB {
+	db = p.filter(db)
+	if p.Cursor != nil {
+		if p.Cursor.value != nil {
+			op := ">"
+			if p.Cursor.desc {
+				op = "<"
+			}
+			db = db.Where(fmt.Sprintf("%s %s ?", p.Cursor.column, op), p.Cursor.value)
+		}
+		if p.Cursor.desc {
+			return db.Order(p.Cursor.column + " DESC").Limit(p.PageSize + 1)
+		}
+		return db.Order(p.Cursor.column + " ASC").Limit(p.PageSize + 1)
+	}
+	for _, order := range p.Orders {
+		db = db.Order(order)
+	}
+	return db.Offset((p.Page - 1) * p.PageSize).Limit(p.PageSize)
+}
+
+func (p *Pager) filter(db *gorm.DB) *gorm.DB {
+	for column, values := range p.Filters {
+		if len(values) == 1 {
+			db = db.Where(column+" = ?", values[0])
+		} else {
+			db = db.Where(column+" IN ?", values)
+		}
+	}
+	return db
+}
+
+//Paginate 按请求参数分页查询，结果保存至dest（切片指针）并返回PageData
+// 页码分页时查询总数并设置X-Total-Count响应头，游标分页不查询总数，total固定为0，通过next_cursor获取下一页
+func Paginate(c *gin.Context, db *gorm.DB, opts PageOptions, dest interface{}) (*PageData, error) {
+	p, err := ParsePage(c, opts)
+	if err != nil {
+		return nil, err
+	}
+	db = db.Session(&gorm.Session{})
+	if db.Statement.Model == nil {
+		db = db.Model(dest)
+	}
+
+	res := &PageData{Result: dest}
+	if p.Cursor == nil {
+		if err = p.filter(db).Count(&res.Total).Error; err != nil {
+			return nil, NewError(ErrDatabase, err)
+		}
+		c.Header("X-Total-Count", strconv.FormatInt(res.Total, 10))
+	}
+	tx := db.Scopes(p.Scope).Find(dest)
+	if tx.Error != nil {
+		return nil, NewError(ErrDatabase, tx.Error)
+	}
+	if p.Cursor != nil {
+		if res.NextCursor, err = p.nextCursor(tx, dest); err != nil {
+			return nil, NewError(ErrResponse, err)
+		}
+	}
+	return res, nil
+}
+
+// nextCursor trims the extra row fetched by Scope and returns the cursor of the next page,
+// or "" on the last page.
+func (p *Pager) nextCursor(tx *gorm.DB, dest interface{}) (string, error) {
+	rv := reflect.ValueOf(dest).Elem()
+	if rv.Len() <= p.PageSize {
+		return "", nil
+	}
+	rv.SetLen(p.PageSize)
+
+	field := tx.Statement.Schema.LookUpField(p.Cursor.column)
+	if field == nil {
+		return "", fmt.Errorf("unknown cursor column `%s`", p.Cursor.column)
+	}
+	value, _ := field.ValueOf(tx.Statement.Context, reflect.Indirect(rv.Index(p.PageSize-1)))
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
@@ -0,0 +1,138 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+var testPageOptions = PageOptions{
+	Sorts:       map[string]string{"id": "id", "name": "user_name"},
+	Filters:     map[string]string{"status": "status"},
+	DefaultSort: "id",
+	Cursor:      "id",
+}
+
+type pageRow struct {
+	ID   uint
+	Name string
+}
+
+func pageContext(query string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/v1/test?"+query, nil)
+	return c
+}
+
+// dryRunDB 只生成SQL，不连接数据库
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(mysql.New(mysql.Config{SkipInitializeWithVersion: true}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestParsePage(t *testing.T) {
+	p, err := ParsePage(pageContext("page=3&page_size=50&sort=-name,id&filter[status]=1,2"), testPageOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Pager{
+		Page:     3,
+		PageSize: 50,
+		Orders:   []string{"user_name DESC", "id ASC"},
+		Filters:  map[string][]string{"status": {"1", "2"}},
+	}
+	if !reflect.DeepEqual(p, want) {
+		t.Fatalf("ParsePage = %+v, want %+v", p, want)
+	}
+
+	p, err = ParsePage(pageContext(""), testPageOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Page != 1 || p.PageSize != DefaultPageSize || !reflect.DeepEqual(p.Orders, []string{"id ASC"}) {
+		t.Fatalf("ParsePage defaults = %+v", p)
+	}
+}
+
+func TestParsePageInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		opts  PageOptions
+	}{
+		{"page", "page=0", testPageOptions},
+		{"page not number", "page=a", testPageOptions},
+		{"page_size zero", "page_size=0", testPageOptions},
+		{"page_size over max", "page_size=201", testPageOptions},
+		{"sort", "sort=password", testPageOptions},
+		{"filter", "filter[password]=1", testPageOptions},
+		{"cursor not supported", "cursor=", PageOptions{Sorts: testPageOptions.Sorts}},
+		{"cursor sort", "cursor=&sort=name", testPageOptions},
+		{"cursor value", "cursor=%21%21", testPageOptions},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParsePage(pageContext(tt.query), tt.opts)
+			var appErr *AppError
+			if !errors.As(err, &appErr) || appErr.Code != ErrValidation {
+				t.Fatalf("ParsePage(%s) = %v, want ErrValidation", tt.query, err)
+			}
+		})
+	}
+
+	if _, err := ParsePage(pageContext("page_size=200"), testPageOptions); err != nil {
+		t.Fatalf("ParsePage with page_size=%d = %v", MaxPageSize, err)
+	}
+}
+
+func TestPageCursor(t *testing.T) {
+	db := dryRunDB(t)
+	p, err := ParsePage(pageContext("cursor=&page_size=2&sort=-id"), testPageOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Scope多查询一行用于判断是否还有下一页
+	rows := []pageRow{{ID: 9}, {ID: 7}, {ID: 5}}
+	tx := db.Scopes(p.Scope).Find(&rows)
+	if sql := tx.Statement.SQL.String(); !strings.HasSuffix(sql, "ORDER BY id DESC LIMIT 3") {
+		t.Fatalf("first page sql = %s", sql)
+	}
+	cursor, err := p.nextCursor(tx, &rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || cursor == "" {
+		t.Fatalf("nextCursor = %q, rows %v", cursor, rows)
+	}
+
+	// 下一页从游标之后开始
+	p, err = ParsePage(pageContext("page_size=2&sort=-id&cursor="+cursor), testPageOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows = []pageRow{{ID: 5}}
+	tx = db.Scopes(p.Scope).Find(&rows)
+	if sql := tx.Statement.SQL.String(); !strings.Contains(sql, "WHERE id < ? ORDER BY id DESC LIMIT 3") {
+		t.Fatalf("next page sql = %s", sql)
+	}
+	if vars := tx.Statement.Vars; len(vars) != 1 || vars[0] != json.Number("7") {
+		t.Fatalf("next page vars = %v, want 7", vars)
+	}
+	if cursor, err = p.nextCursor(tx, &rows); err != nil || cursor != "" {
+		t.Fatalf("nextCursor of the last page = %q, %v", cursor, err)
+	}
+}
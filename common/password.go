@@ -0,0 +1,82 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"go-api/utils"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+//HashPassword 使用配置的算法生成密码哈希
+func HashPassword(password string) (string, error) {
+	return utils.PasswordHashWith(strings.ToLower(CONFIG.Password.Algorithm), password)
+}
+
+// dummyHash 用户不存在时比较的哈希，首次使用时按配置的算法生成
+var (
+	dummyHash     string
+	dummyHashOnce sync.Once
+)
+
+//ComparePassword 校验明文密码与哈希是否一致
+// hash为空（用户不存在）时仍与假哈希比较，使耗时与用户存在时一致，避免通过响应时间探测用户名
+func ComparePassword(hash, password string) bool {
+	if hash == "" {
+		dummyHashOnce.Do(func() {
+			dummyHash, _ = HashPassword("dummy password")
+		})
+		utils.PasswordCompare(dummyHash, password)
+		return false
+	}
+	return utils.PasswordCompare(hash, password)
+}
+
+//CheckPasswordRule 按密码策略检查复杂度，返回所有不满足的规则
+func CheckPasswordRule(password string) error {
+	policy := CONFIG.Password
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+
+	var rules []string
+	if length := len([]rune(password)); length < policy.MinLength {
+		rules = append(rules, fmt.Sprintf("at least %d characters", policy.MinLength))
+	}
+	if policy.RequireUpper && !upper {
+		rules = append(rules, "an upper case letter")
+	}
+	if policy.RequireLower && !lower {
+		rules = append(rules, "a lower case letter")
+	}
+	if policy.RequireDigit && !digit {
+		rules = append(rules, "a digit")
+	}
+	if policy.RequireSymbol && !symbol {
+		rules = append(rules, "a symbol")
+	}
+	if len(rules) > 0 {
+		return errors.New("the password must contain " + strings.Join(rules, ", "))
+	}
+	return nil
+}
+
+//PasswordExpired 根据最后修改时间判断密码是否已过期，MaxAge为0时不过期
+func PasswordExpired(changedAt time.Time) bool {
+	if CONFIG.Password.MaxAge <= 0 {
+		return false
+	}
+	return time.Since(changedAt) > time.Duration(CONFIG.Password.MaxAge)*24*time.Hour
+}
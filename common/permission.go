@@ -0,0 +1,70 @@
+package common
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 路由声明使用的权限
+const (
+	PermAll          = "*"             // 全部权限
+	PermSessionAdmin = "session:admin" // 查看及注销任意用户的session
+	PermApiKeyManage = "apikey:manage" // 创建、轮换及吊销自己的API Key
+	PermErrnoManage  = "errno:manage"  // 查看及修改错误信息
+	PermLogManage    = "log:manage"    // 查看及修改运行时的日志级别
+)
+
+//HasPermission 判断session是否拥有指定权限
+func (s *Session) HasPermission(perm string) bool {
+	for _, p := range s.Permissions {
+		if p == perm || p == PermAll {
+			return true
+		}
+	}
+	return false
+}
+
+// intersectPermissions 同时包含在两个列表中的权限，PermAll 匹配另一列表中的全部权限
+func intersectPermissions(a, b []string) []string {
+	res := []string{}
+	for _, p := range a {
+		for _, q := range b {
+			switch {
+			case p == q, q == PermAll:
+				res = append(res, p)
+			case p == PermAll:
+				res = append(res, q)
+			}
+		}
+	}
+	return dedupPermissions(res)
+}
+
+func dedupPermissions(perms []string) []string {
+	seen := make(map[string]bool, len(perms))
+	res := perms[:0]
+	for _, p := range perms {
+		if !seen[p] {
+			seen[p] = true
+			res = append(res, p)
+		}
+	}
+	return res
+}
+
+//RequirePermission 返回校验指定权限的中间件，需在SessionCheck之后使用
+func RequirePermission(perm string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		se := CurrentSession(c)
+		if se == nil || !se.HasPermission(perm) {
+			Abort(ErrPermission, fmt.Errorf("missing permission `%s`", perm), c)
+			return
+		}
+		if apiKeyForbidden(c, se) {
+			Abort(ErrPermission, errApiKeyAccount, c)
+			return
+		}
+		c.Next()
+	}
+}
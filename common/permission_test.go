@@ -0,0 +1,29 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIntersectPermissions(t *testing.T) {
+	tests := []struct {
+		name   string
+		scopes []string
+		owned  []string
+		want   []string
+	}{
+		{"both", []string{"a", "b"}, []string{"b", "c"}, []string{"b"}},
+		{"scope all", []string{PermAll}, []string{"a", "b"}, []string{"a", "b"}},
+		{"owner all", []string{"a", "b"}, []string{PermAll}, []string{"a", "b"}},
+		{"both all", []string{PermAll}, []string{PermAll, "a"}, []string{PermAll, "a"}},
+		{"owner demoted", []string{PermAll}, nil, []string{}},
+		{"duplicate", []string{"a", "a"}, []string{"a", PermAll}, []string{"a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := intersectPermissions(tt.scopes, tt.owned); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("intersectPermissions(%v, %v) = %v, want %v", tt.scopes, tt.owned, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package common
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+	"gopkg.in/yaml.v3"
+)
+
+// renderFormat is a response format of SuccessReturn, the first mime is the Content-Type.
+type renderFormat struct {
+	name   string
+	mimes  []string
+	render func(c *gin.Context, code int, req *Req) error
+}
+
+//renderFormats SuccessReturn支持的响应格式，未指定时使用第一个
+var renderFormats = []renderFormat{
+	// 错误使用problem+json的客户端，成功时仍返回json
+	{name: "json", mimes: []string{"application/json", ProblemContentType}, render: renderJSON},
+	{name: "msgpack", mimes: []string{"application/msgpack", "application/x-msgpack"}, render: renderMsgPack},
+	{name: "yaml", mimes: []string{"application/yaml", "application/x-yaml", "text/yaml"}, render: renderYAML},
+	{name: "csv", mimes: []string{"text/csv"}, render: renderCSV},
+}
+
+// negotiateFormat picks the format from the `format` query param, or the Accept header
+// ordered by quality. It returns nil when nothing matches.
+func negotiateFormat(c *gin.Context) *renderFormat {
+	if name := c.Query("format"); name != "" {
+		for i := range renderFormats {
+			if renderFormats[i].name == strings.ToLower(name) {
+				return &renderFormats[i]
+			}
+		}
+		return nil
+	}
+	accept := c.GetHeader("Accept")
+	if strings.TrimSpace(accept) == "" {
+		return &renderFormats[0]
+	}
+	for _, mime := range qualityValues(accept) {
+		for i := range renderFormats {
+			for _, m := range renderFormats[i].mimes {
+				if mime == "*/*" || mime == m || (strings.HasSuffix(mime, "/*") && strings.HasPrefix(m, mime[:len(mime)-1])) {
+					return &renderFormats[i]
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// supportedFormats lists the names and mime types of the formats for the 406 response.
+func supportedFormats() map[string]interface{} {
+	var names, mimes []string
+	for _, f := range renderFormats {
+		names = append(names, f.name)
+		mimes = append(mimes, f.mimes...)
+	}
+	return map[string]interface{}{"formats": names, "accept": mimes}
+}
+
+// renderFormatKey is the context key of the format chosen by Negotiate.
+const renderFormatKey = "render_format"
+
+// Negotiate picks the response format before the handler runs, so a request asking for
+// an unsupported format is answered 406 without the side effects of the handler.
+func Negotiate(c *gin.Context) {
+	// 未匹配任何路由的请求交由404处理
+	if c.FullPath() == "" {
+		c.Next()
+		return
+	}
+	c.Header("Vary", "Accept")
+	f := negotiateFormat(c)
+	if f == nil {
+		abort(c, ErrNotAcceptable, errors.New("none of the requested formats is supported"), supportedFormats(), callers(1))
+		return
+	}
+	c.Set(renderFormatKey, f)
+	c.Next()
+}
+
+// renderReq writes the envelope in the format chosen by Negotiate, engines without the
+// middleware negotiate here and abort with ErrNotAcceptable.
+func renderReq(c *gin.Context, code int, req *Req) {
+	c.Header("Vary", "Accept")
+	f, _ := c.Value(renderFormatKey).(*renderFormat)
+	if f == nil {
+		f = negotiateFormat(c)
+	}
+	if f == nil {
+		abort(c, ErrNotAcceptable, errors.New("none of the requested formats is supported"), supportedFormats(), callers(2))
+		return
+	}
+	if err := f.render(c, code, req); err != nil {
+		abort(c, ErrResponse, err, map[string]interface{}{"format": f.name}, callers(2))
+	}
+}
+
+func renderJSON(c *gin.Context, code int, req *Req) error {
+	c.JSON(code, req)
+	return nil
+}
+
+func renderMsgPack(c *gin.Context, code int, req *Req) error {
+	c.Render(code, render.MsgPack{Data: req})
+	return nil
+}
+
+// renderYAML converts the json encoding to yaml, so the keys and their order follow the json tags.
+func renderYAML(c *gin.Context, code int, req *Req) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	var node yaml.Node
+	if err = yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	blockStyle(&node)
+	if data, err = yaml.Marshal(&node); err != nil {
+		return err
+	}
+	c.Data(code, "application/yaml; charset=utf-8", data)
+	return nil
+}
+
+// blockStyle clears the flow and quoted styles the json input left on the nodes.
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, n := range node.Content {
+		blockStyle(n)
+	}
+}
+
+// renderCSV writes the result rows with a header line, PageData is flattened to its Result.
+// Nested objects and arrays are written as json.
+func renderCSV(c *gin.Context, code int, req *Req) error {
+	data := req.Data
+	switch page := data.(type) {
+	case PageData:
+		data = page.Result
+	case *PageData:
+		data = page.Result
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	var rows []json.RawMessage
+	if err = json.Unmarshal(raw, &rows); err != nil {
+		// 非列表数据作为一行
+		rows = []json.RawMessage{raw}
+	}
+
+	var columns []string
+	seen := make(map[string]bool)
+	records := make([]map[string]json.RawMessage, 0, len(rows))
+	for _, row := range rows {
+		keys, values, err := jsonObject(row)
+		if err != nil {
+			return err
+		}
+		for _, k := range keys {
+			if !seen[k] {
+				seen[k] = true
+				columns = append(columns, k)
+			}
+		}
+		records = append(records, values)
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err = w.Write(columns); err != nil {
+		return err
+	}
+	for _, values := range records {
+		record := make([]string, len(columns))
+		for i, col := range columns {
+			record[i] = csvCell(values[col])
+		}
+		if err = w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	if err = w.Error(); err != nil {
+		return err
+	}
+	c.Data(code, "text/csv; charset=utf-8", buf.Bytes())
+	return nil
+}
+
+// jsonObject decodes a json object keeping the order of its keys, other values become
+// a single `value` column.
+func jsonObject(raw json.RawMessage) ([]string, map[string]json.RawMessage, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return []string{"value"}, map[string]json.RawMessage{"value": raw}, nil
+	}
+	var keys []string
+	values := make(map[string]json.RawMessage)
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		key := tok.(string)
+		var value json.RawMessage
+		if err = dec.Decode(&value); err != nil {
+			return nil, nil, err
+		}
+		keys = append(keys, key)
+		values[key] = value
+	}
+	return keys, values, nil
+}
+
+// csvCell returns strings unquoted, null as empty, and other values as json.
+func csvCell(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+	var s string
+	if raw[0] == '"' && json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	return string(raw)
+}
@@ -0,0 +1,58 @@
+package common
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestQualityValues(t *testing.T) {
+	got := qualityValues("text/csv;q=0.5, Application/JSON, application/yaml;q=0, */*;q=0.1")
+	want := []string{"application/json", "text/csv", "*/*"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("qualityValues = %v, want %v", got, want)
+	}
+	if got = acceptLanguages("zh_CN;q=0.8, *, en"); !reflect.DeepEqual(got, []string{"en", "zh-cn"}) {
+		t.Fatalf("acceptLanguages = %v", got)
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var calls int
+	r := gin.New()
+	r.Use(Negotiate)
+	r.GET("/v1/test", func(c *gin.Context) {
+		calls++
+		SuccessReturn("ok", c)
+	})
+
+	tests := []struct {
+		accept      string
+		status      int
+		calls       int
+		contentType string
+	}{
+		{"", http.StatusOK, 1, "application/json; charset=utf-8"},
+		{ProblemContentType, http.StatusOK, 1, "application/json; charset=utf-8"},
+		{"text/csv;q=0.5, application/yaml", http.StatusOK, 1, "application/yaml; charset=utf-8"},
+		// 不支持的格式在handler执行前返回406
+		{"text/html", http.StatusNotAcceptable, 0, "application/json; charset=utf-8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			calls = 0
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/v1/test", nil)
+			req.Header.Set("Accept", tt.accept)
+			r.ServeHTTP(w, req)
+			if w.Code != tt.status || calls != tt.calls || w.Header().Get("Content-Type") != tt.contentType {
+				t.Fatalf("Accept %q = %d %s with %d handler calls, want %d %s with %d",
+					tt.accept, w.Code, w.Header().Get("Content-Type"), calls, tt.status, tt.contentType, tt.calls)
+			}
+		})
+	}
+}
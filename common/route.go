@@ -0,0 +1,73 @@
+package common
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+//RouteAccess 路由的访问规则
+type RouteAccess struct {
+	Method               string
+	Path                 string // gin的路由规则，如 /v1/auth/sessions/:handle
+	Public               bool   // 无需认证
+	Permission           string // 需要的权限，为空时登录即可访问
+	AllowPasswordExpired bool   // 密码过期的session也可访问，如修改密码
+}
+
+// routeRegistry 已声明访问规则的路由，key为 METHOD PATH
+var routeRegistry = struct {
+	sync.RWMutex
+	routes map[string]RouteAccess
+}{routes: make(map[string]RouteAccess)}
+
+func routeKey(method, path string) string {
+	return method + " " + path
+}
+
+//RegisterRoute 声明路由的访问规则，未声明的路由默认需要认证
+func RegisterRoute(access RouteAccess) {
+	routeRegistry.Lock()
+	defer routeRegistry.Unlock()
+	routeRegistry.routes[routeKey(access.Method, access.Path)] = access
+}
+
+//IsPublicRoute 按完整的路由规则判断是否无需认证
+func IsPublicRoute(method, path string) bool {
+	routeRegistry.RLock()
+	defer routeRegistry.RUnlock()
+	return routeRegistry.routes[routeKey(method, path)].Public
+}
+
+//AllowsPasswordExpired 按完整的路由规则判断密码过期的session是否可以访问
+func AllowsPasswordExpired(method, path string) bool {
+	routeRegistry.RLock()
+	defer routeRegistry.RUnlock()
+	return routeRegistry.routes[routeKey(method, path)].AllowPasswordExpired
+}
+
+//LogRoutes 启动时打印所有路由及其访问规则
+func LogRoutes(routes gin.RoutesInfo) {
+	routeRegistry.RLock()
+	defer routeRegistry.RUnlock()
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path == routes[j].Path {
+			return routes[i].Method < routes[j].Method
+		}
+		return routes[i].Path < routes[j].Path
+	})
+	LogInfo(fmt.Sprintf("%-7s %-45s %-13s %s", "METHOD", "PATH", "ACCESS", "PERMISSION"))
+	for _, r := range routes {
+		access := routeRegistry.routes[routeKey(r.Method, r.Path)]
+		kind := "authenticated"
+		if access.Public {
+			kind = "public"
+		} else if access.AllowPasswordExpired {
+			kind = "restricted"
+		}
+		LogInfo(strings.TrimSpace(fmt.Sprintf("%-7s %-45s %-13s %s", r.Method, r.Path, kind, access.Permission)))
+	}
+}
@@ -1,65 +1,136 @@
 package common
 
 import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/gomodule/redigo/redis"
 	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
 )
 
-// SessionHash 记录登陆session的哈希表
+// SessionHash 记录登陆session的哈希表前缀，每个用户一个哈希表 SessionLogin_<userid>
+// 字段为session_id，值为注册时间（纳秒），用于踢掉最早的session
 const SessionHash = "SessionLogin"
 
+// sessionTouchInterval 请求时更新最后访问时间的最小间隔，单位秒
+const sessionTouchInterval = 60
+
+// session状态
+const (
+	SessionStateActive          = ""                 // 已完成登录
+	SessionStateMfaPending      = "mfa_pending"      // 密码校验通过，等待两步验证
+	SessionStatePasswordExpired = "password_expired" // 密码已过期，只能访问修改密码的接口
+)
+
+// sessionIDBytes sessionID中随机数的字节数
+const sessionIDBytes = 32
+
+// sessionSecret 用于sessionID签名的密钥，来自--jwt-secret
+var sessionSecret []byte
+
+// errSessionID sessionID格式错误或签名不一致
+var errSessionID = errors.New("the session id is malformed or has been tampered with")
+
 //Session 保存信息
 type Session struct {
-	SessionID string `json:"session_id"` // SessionID
-	UserID    string `json:"user_id"`    // 用户ID
-	UserName  string `json:"user_name"`  // 用户名
-	Lock      bool   `json:"lock"`       // 锁定
-	Appid     string `json:"appid"`
+	SessionID   string   `json:"session_id"` // SessionID
+	UserID      string   `json:"user_id"`    // 用户ID
+	UserName    string   `json:"user_name"`  // 用户名
+	Lock        bool     `json:"lock"`       // 锁定
+	Appid       string   `json:"appid"`
+	Roles       []string `json:"roles"`       // 角色
+	Permissions []string `json:"permissions"` // 角色对应的权限
+	CreatedAt   int64    `json:"created_at"`  // 登录时间，用于计算最长存活时间
+	LastSeen    int64    `json:"last_seen"`   // 最后访问时间
+	Device      string   `json:"device"`      // 登录设备
+	IP          string   `json:"ip"`          // 最后访问IP
+	UserAgent   string   `json:"user_agent"`
+	State       string   `json:"state,omitempty"` // session状态，为空时已完成登录
+	Lang        string   `json:"lang,omitempty"`  // 错误信息的语言偏好
+	ApiKey      bool     `json:"-"`               // 通过API Key认证，不能访问账户接口，也不签发token
+}
+
+//SessionInit 初始化sessionID的签名密钥
+func SessionInit(c *cli.Context) {
+	sessionSecret = []byte(c.String("jwt-secret"))
 }
 
 //CreateSessionID 创建sessionID
-func (s *Session) CreateSessionID() (res string) {
-	res = fmt.Sprintf("Miku_%s_%s_%d", s.Appid, s.UserID, time.Now().Unix())
+// 格式为 随机数.签名，均为base64url编码，签名为随机数的HMAC-SHA256
+func (s *Session) CreateSessionID() (res string, err error) {
+	random := make([]byte, sessionIDBytes)
+	if _, err = rand.Read(random); err != nil {
+		return
+	}
+	res = base64.RawURLEncoding.EncodeToString(random) + "." + base64.RawURLEncoding.EncodeToString(signSessionID(random))
 	return
 }
 
+//VerifySessionID 校验sessionID的格式及签名
+func (s *Session) VerifySessionID() bool {
+	parts := strings.Split(s.SessionID, ".")
+	if len(parts) != 2 {
+		return false
+	}
+	random, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || len(random) != sessionIDBytes {
+		return false
+	}
+	sign, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(sign, signSessionID(random))
+}
+
 //GetSessionUserID 根据sessionID获取用户ID
-func (s *Session) GetSessionUserID() {
-	sessionSlice := strings.Split(s.SessionID, "_")
-	s.Appid = sessionSlice[1]
-	s.UserID = sessionSlice[2]
-	return
+// sessionID本身不包含用户信息，校验签名后从保存的session中读取
+func (s *Session) GetSessionUserID() (err error) {
+	if !s.VerifySessionID() {
+		return errSessionID
+	}
+	return s.GetSession()
+}
+
+func signSessionID(random []byte) []byte {
+	mac := hmac.New(sha256.New, sessionSecret)
+	mac.Write(random)
+	return mac.Sum(nil)
 }
 
 //SessionCheck 检查
 func SessionCheck(c *gin.Context) {
-	path := strings.Split(c.Request.URL.Path, "/")
-	if path[len(path)-1] == "health" || path[len(path)-1] == "tenant" {
+	// 无需认证的路由，以及未匹配任何路由的请求（交由404处理）
+	if c.FullPath() == "" || IsPublicRoute(c.Request.Method, c.FullPath()) {
 		c.Next()
 		return
 	}
 
-	// 无需session的接口
-	switch path[len(path)-1] {
-	case "login", "logout", "favicon.ico", "token", "licenses", "upgrade", "passwordmsg", "execute", "auth", "results", "receive", "outworker", "terminal":
-		c.Next()
+	// 服务间调用使用API Key
+	if key := c.GetHeader("X-Api-Key"); key != "" {
+		apiKeyCheck(c, key)
+		if se := CurrentSession(c); !c.IsAborted() && se != nil && apiKeyForbidden(c, se) {
+			Abort(ErrPermission, errApiKeyAccount, c)
+		}
 		return
 	}
-	// 绕过B站接口
-	if strings.Contains(c.Request.URL.Path, "bili") {
-		c.Next()
+	// 携带Bearer token时优先使用JWT认证
+	if token := bearerToken(c); token != "" && AuthJwtEnabled() {
+		tokenCheck(c, token)
 		return
 	}
-
 	Session_id := c.Request.Header.Get("Session")
-	if len(Session_id) == 0 {
+	if len(Session_id) == 0 || !AuthSessionEnabled() {
 		Abort(ErrMissingAuthorization, nil, c)
 		return
 	}
@@ -67,150 +138,257 @@ func SessionCheck(c *gin.Context) {
 		SessionID: Session_id,
 	}
 	//根据session_id获取用户id
-	se.GetSessionUserID()
+	if err := se.GetSessionUserID(); err != nil {
+		Abort(ErrSession, err, c)
+		return
+	}
 	// 检查session是否存在且一致未过期
-	status, err := se.CheckSession()
+	status, err := se.CheckSession(c)
 	if !status || err != nil {
 		Abort(ErrSession, err, c)
 		return
-	} else {
-		// 获取session具体信息
-		if err = se.GetSession(); err != nil {
-			Abort(ErrSession, nil, c)
-			return
+	}
+	if !checkSessionState(c, &se) {
+		return
+	}
+	// 超过最长存活时间的session直接删除
+	if se.Expired() {
+		_ = se.DeleteSession(c)
+		Abort(ErrSession, errors.New("the session has reached its maximum lifetime"), c)
+		return
+	}
+	// 滑动过期，每次请求重新设置过期时间
+	ttl, err := se.Touch(c.ClientIP())
+	if err != nil {
+		Abort(ErrSession, err, c)
+		return
+	}
+	c.Header("X-Session-Expires-In", strconv.Itoa(ttl))
+	if CONFIG.SessionMaxLifetime > 0 {
+		c.Header("X-Session-Lifetime", strconv.Itoa(se.Lifetime()))
+	}
+
+	setSessionContext(c, &se)
+}
+
+// tokenCheck 校验JWT，与session认证设置相同的上下文
+func tokenCheck(c *gin.Context, token string) {
+	se, expiresAt, code, err := ParseToken(token)
+	if err != nil {
+		Abort(code, err, c)
+		return
+	}
+	if !checkSessionState(c, se) {
+		return
+	}
+	c.Header("X-Session-Expires-In", strconv.Itoa(int(time.Until(expiresAt).Seconds())))
+	if CONFIG.SessionMaxLifetime > 0 {
+		c.Header("X-Session-Lifetime", strconv.Itoa(se.Lifetime()))
+	}
+
+	setSessionContext(c, se)
+}
+
+// bearerToken 获取Authorization请求头中的Bearer token
+func bearerToken(c *gin.Context) string {
+	auth := c.GetHeader("Authorization")
+	if len(auth) > 7 && strings.EqualFold(auth[:7], "Bearer ") {
+		return strings.TrimSpace(auth[7:])
+	}
+	return ""
+}
+
+// checkSessionState 未完成登录的session不能访问，密码过期的session只能访问允许的路由
+func checkSessionState(c *gin.Context, se *Session) bool {
+	switch se.State {
+	case SessionStateActive:
+		return true
+	case SessionStatePasswordExpired:
+		if AllowsPasswordExpired(c.Request.Method, c.FullPath()) {
+			return true
 		}
+		Abort(ErrPasswordExpired, errors.New("the password has expired, change it before using other apis"), c)
+	default:
+		Abort(ErrGoogleVerify, errors.New("the session is waiting for the second factor"), c)
 	}
+	return false
+}
 
+// setSessionContext 将认证后的用户信息保存至请求上下文
+func setSessionContext(c *gin.Context, se *Session) {
+	c.Set("session", se)
 	c.Set("userid", se.UserID)
 	c.Set("username", se.UserName)
 	c.Set("lock", se.Lock)
+	c.Set("roles", se.Roles)
+	WithLogFields(c, logrus.Fields{"user_id": se.UserID})
+}
+
+//CurrentSession 获取当前请求认证后的session信息
+func CurrentSession(c *gin.Context) *Session {
+	if se, ok := c.Get("session"); ok {
+		return se.(*Session)
+	}
+	return nil
 }
 
 //SessionRegister session 注册
 // 设置session_id及其过期时间，并保存记录session_id 用于判断单用户登陆
-func (s *Session) SessionRegister() (err error) {
-	conn := Pool.Get()
-	defer conn.Close()
-	if conn == nil {
-		return errors.New("redis connection is nil")
+func (s *Session) SessionRegister(ctx context.Context) (err error) {
+	if s.CreatedAt == 0 {
+		s.CreatedAt = time.Now().Unix()
+	}
+	if s.LastSeen == 0 {
+		s.LastSeen = s.CreatedAt
 	}
 	var value []byte
 	value, err = json.Marshal(s)
 	if err != nil {
 		return
 	}
-	// 记录session_id
-	_, err = conn.Do("SET", s.SessionID, value)
-	if err != nil {
-		LogErrorf("set session_id error", logrus.Fields{"err": err})
-		return
-	}
-	// 设置过期时间
-	if _, err = conn.Do("EXPIRE", s.SessionID, CONFIG.SessionExpireTime); err != nil {
-		LogErrorf("EXPIRE session_id error", logrus.Fields{"err": err})
+	// 记录session_id并设置过期时间
+	if err = Store.Set(s.SessionID, value, s.idleTTL()); err != nil {
+		LogErrorCtx(ctx, "set session_id error", logrus.Fields{"err": err})
 		return
 	}
 	// 保存当前session
-	if _, err = conn.Do("HSET", SessionHash, fmt.Sprintf("%s_%s", s.Appid, s.UserID), s.SessionID); err != nil {
-		LogErrorf("hset sessionHash error", logrus.Fields{"err": err})
+	if err = Store.HSet(s.indexKey(), s.SessionID, strconv.FormatInt(time.Now().UnixNano(), 10)); err != nil {
+		LogErrorCtx(ctx, "hset sessionHash error", logrus.Fields{"err": err})
 		return
 	}
-	return
+	// 根据策略踢掉多余的session
+	return s.applySessionPolicy(ctx)
 }
 
 //CheckSession 检查session，用于每次请求判断
 //判断是否是有符合的用户登陆且session_id一致, 否 则要退出重新登录
-func (s *Session) CheckSession() (status bool, err error) {
-	conn := Pool.Get()
-	defer conn.Close()
-	if conn == nil {
-		return false, errors.New("redis connection is nil")
-	}
-	if status, err = redis.Bool(conn.Do("HEXISTS", SessionHash, fmt.Sprintf("%s_%s", s.Appid, s.UserID))); err != nil || !status {
-		status = false
-		LogErrorf("redis HEXISTS Session HashMap error,may be the param session is not equal to the save one", logrus.Fields{"err": err})
-		return
-	} else {
-		//  判断是否和记录的session_id一致
-		exisSessionid, _ := redis.String(conn.Do("HGET", SessionHash, fmt.Sprintf("%s_%s", s.Appid, s.UserID)))
-		if exisSessionid != s.SessionID {
-			return false, nil
-		}
-		// 如果存在记录session_id ,且还未过期
-		if status, err = redis.Bool(conn.Do("EXISTS", s.SessionID)); err != nil || !status {
-			status = false
-			LogErrorf("redis EXISTS session_id error,or is already expired", logrus.Fields{"err": err})
-			return
-		} else {
-			return
-		}
+func (s *Session) CheckSession(ctx context.Context) (status bool, err error) {
+	//  判断用户的session记录中是否存在该session_id
+	if _, err = Store.HGet(s.indexKey(), s.SessionID); err == ErrStoreNil {
+		return false, nil
+	} else if err != nil {
+		LogErrorCtx(ctx, "HGET Session HashMap error", logrus.Fields{"err": err})
+		return false, err
+	}
+	// 如果存在记录session_id ,且还未过期
+	if status, err = Store.Exists(s.SessionID); err != nil || !status {
+		LogErrorCtx(ctx, "EXISTS session_id error,or is already expired", logrus.Fields{"err": err})
+		return false, err
 	}
+	return
 }
 
 //SetSessionExpire 设置过期时间,用于每次请求不断重新设置过期时间
-func (s *Session) SetSessionExpire() (err error) {
-	conn := Pool.Get()
-	if conn == nil {
-		return errors.New("redis connection is nil")
+// 返回设置的过期秒数
+func (s *Session) SetSessionExpire() (ttl int, err error) {
+	ttl = s.idleTTL()
+	err = Store.Expire(s.SessionID, ttl)
+	return
+}
+
+//Touch 记录最后访问时间及IP并重新设置过期时间
+// 为减少写入，距上次记录不足sessionTouchInterval时只重置过期时间
+func (s *Session) Touch(ip string) (ttl int, err error) {
+	now := time.Now().Unix()
+	if now-s.LastSeen < sessionTouchInterval && s.IP == ip {
+		return s.SetSessionExpire()
 	}
-	defer conn.Close()
-	_, err = conn.Do("EXPIRE", s.SessionID, CONFIG.SessionExpireTime)
-	if err != nil {
+	s.LastSeen = now
+	s.IP = ip
+	if err = s.SetSession(); err != nil {
 		return
 	}
+	return s.idleTTL(), nil
+}
+
+//Lifetime 距离最长存活时间的剩余秒数，未配置SessionMaxLifetime时返回-1
+func (s *Session) Lifetime() int {
+	if CONFIG.SessionMaxLifetime <= 0 {
+		return -1
+	}
+	return int(s.CreatedAt + int64(CONFIG.SessionMaxLifetime) - time.Now().Unix())
+}
+
+//Expired 是否已超过最长存活时间
+func (s *Session) Expired() bool {
+	return CONFIG.SessionMaxLifetime > 0 && s.Lifetime() <= 0
+}
+
+// idleTTL 空闲超时时间，不超过剩余的最长存活时间
+func (s *Session) idleTTL() (ttl int) {
+	ttl = CONFIG.SessionIdleTimeout
+	if ttl <= 0 {
+		ttl = CONFIG.SessionExpireTime
+	}
+	if lifetime := s.Lifetime(); lifetime >= 0 && lifetime < ttl {
+		ttl = lifetime
+	}
 	return
 }
 
+// indexKey 记录用户所有session的哈希表
+func (s *Session) indexKey() string {
+	return fmt.Sprintf("%s_%s", SessionHash, s.UserID)
+}
+
 //SetSession 设置session
 func (s *Session) SetSession() (err error) {
-	conn := Pool.Get()
-	if conn == nil {
-		return errors.New("redis connection is nil")
-	}
-	defer conn.Close()
 	var value []byte
 	value, err = json.Marshal(s)
 	if err != nil {
 		return
 	}
-	_, err = conn.Do("SET", s.SessionID, value)
-	if err != nil {
-		return
-	}
-	return
+	return Store.Set(s.SessionID, value, s.idleTTL())
 }
 
 //GetSession 获取session
 func (s *Session) GetSession() (err error) {
-	conn := Pool.Get()
-	if conn == nil {
-		return errors.New("redis connection is nil")
-	}
-	defer conn.Close()
 	var unMarshal []byte
-	unMarshal, err = redis.Bytes(conn.Do("GET", s.SessionID))
+	unMarshal, err = Store.Get(s.SessionID)
+	if err != nil {
+		return
+	}
 	err = json.Unmarshal(unMarshal, s)
 	return
 }
 
+//RefreshSession 刷新session，注册新的session_id并使旧的session_id失效
+func (s *Session) RefreshSession(ctx context.Context) (res *Session, err error) {
+	// 保留登录时间，刷新不会延长最长存活时间
+	res = &Session{
+		UserID:      s.UserID,
+		UserName:    s.UserName,
+		Lock:        s.Lock,
+		Appid:       s.Appid,
+		Roles:       s.Roles,
+		Permissions: s.Permissions,
+		CreatedAt:   s.CreatedAt,
+		Device:      s.Device,
+		IP:          s.IP,
+		UserAgent:   s.UserAgent,
+		State:       s.State,
+		Lang:        s.Lang,
+	}
+	if res.SessionID, err = res.CreateSessionID(); err != nil {
+		return nil, err
+	}
+	if err = res.SessionRegister(ctx); err != nil {
+		return nil, err
+	}
+	if err = s.DeleteSession(ctx); err != nil {
+		LogErrorCtx(ctx, "RefreshSession, delete old session_id error", logrus.Fields{"err": err})
+	}
+	return res, nil
+}
+
 //DeleteSession 删除用户登陆信息
-func (s *Session) DeleteSession() (err error) {
-	conn := Pool.Get()
-	if conn == nil {
-		return errors.New("redis connection is nil")
-	}
-	defer conn.Close()
-	_, err = conn.Do("HDEL", SessionHash, s.UserID)
-	var status bool
-	if status, err = redis.Bool(conn.Do("HEXISTS", SessionHash, fmt.Sprintf("%s_%s", s.Appid, s.UserID))); err != nil {
-		LogErrorf("redis DeleteSession ,HEXISTS User Token Error ", logrus.Fields{"err": err, "status": status})
-	} else if !status {
-		LogInfo("redis DeleteSession Success User Is Not Login")
-	} else if status {
-		_, err = conn.Do("HDEL", SessionHash, fmt.Sprintf("%s_%s", s.Appid, s.UserID))
-		if err != nil {
-			LogErrorf("redis DeleteSession ,HDEL Error ", logrus.Fields{"err": err, "status": status})
-		}
+func (s *Session) DeleteSession(ctx context.Context) (err error) {
+	if err = Store.Del(s.SessionID); err != nil {
+		LogErrorCtx(ctx, "DeleteSession, DEL session_id Error ", logrus.Fields{"err": err})
+		return
+	}
+	if err = Store.HDel(s.indexKey(), s.SessionID); err != nil {
+		LogErrorCtx(ctx, "DeleteSession ,HDEL Error ", logrus.Fields{"err": err})
 	}
 	return
 }
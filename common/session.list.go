@@ -0,0 +1,143 @@
+package common
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 同一用户的session数量策略
+const (
+	SessionPolicySingle    = "single"    // 仅保留最新登录的session
+	SessionPolicyLimit     = "limit"     // 最多保留MaxSessions个session
+	SessionPolicyUnlimited = "unlimited" // 不限制
+)
+
+//SessionInfo 用户session列表中展示的信息，不包含session_id本身
+type SessionInfo struct {
+	Handle    string `json:"handle"` // session_id的摘要，用于注销指定的session
+	Appid     string `json:"appid"`
+	Device    string `json:"device"`
+	IP        string `json:"ip"`
+	UserAgent string `json:"user_agent"`
+	CreatedAt int64  `json:"created_at"`
+	LastSeen  int64  `json:"last_seen"`
+	Current   bool   `json:"current"` // 是否为当前请求使用的session
+}
+
+//Handle session_id的摘要，对外展示时代替session_id
+func (s *Session) Handle() string {
+	sum := sha256.Sum256([]byte(s.SessionID))
+	return hex.EncodeToString(sum[:8])
+}
+
+// maxSessions 策略允许的session数量，为0时不限制
+func maxSessions() int {
+	switch strings.ToLower(CONFIG.SessionPolicy.Mode) {
+	case "", SessionPolicySingle:
+		return 1
+	case SessionPolicyLimit:
+		if CONFIG.SessionPolicy.MaxSessions > 0 {
+			return CONFIG.SessionPolicy.MaxSessions
+		}
+		return 1
+	default:
+		return 0
+	}
+}
+
+// applySessionPolicy 清理已过期的记录，并按注册时间踢掉超出数量的session
+func (s *Session) applySessionPolicy(ctx context.Context) (err error) {
+	sessions, err := UserSessions(s.UserID)
+	if err != nil {
+		return
+	}
+	limit := maxSessions()
+	if limit == 0 || len(sessions) <= limit {
+		return
+	}
+	for _, se := range sessions[:len(sessions)-limit] {
+		if err = se.DeleteSession(ctx); err != nil {
+			return
+		}
+		LogInfoCtx(ctx, "session kicked out by session policy", logrus.Fields{"user_id": se.UserID, "handle": se.Handle()})
+	}
+	return
+}
+
+//UserSessions 获取用户所有有效的session，按注册时间升序排列
+// 已过期的session会从记录中删除
+func UserSessions(userID string) (sessions []*Session, err error) {
+	index := (&Session{UserID: userID}).indexKey()
+	fields, err := Store.HGetAll(index)
+	if err != nil {
+		return
+	}
+	registered := make(map[string]int64, len(fields))
+	for sessionID, value := range fields {
+		se := &Session{SessionID: sessionID}
+		if err = se.GetSession(); err == ErrStoreNil {
+			_ = Store.HDel(index, sessionID)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		registered[sessionID], _ = strconv.ParseInt(value, 10, 64)
+		sessions = append(sessions, se)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return registered[sessions[i].SessionID] < registered[sessions[j].SessionID]
+	})
+	return sessions, nil
+}
+
+//ListSessions 获取用户的session列表，current为当前请求的session_id
+func ListSessions(userID, current string) (res []SessionInfo, err error) {
+	sessions, err := UserSessions(userID)
+	if err != nil {
+		return
+	}
+	res = make([]SessionInfo, 0, len(sessions))
+	for _, se := range sessions {
+		res = append(res, SessionInfo{
+			Handle:    se.Handle(),
+			Appid:     se.Appid,
+			Device:    se.Device,
+			IP:        se.IP,
+			UserAgent: se.UserAgent,
+			CreatedAt: se.CreatedAt,
+			LastSeen:  se.LastSeen,
+			Current:   se.SessionID == current,
+		})
+	}
+	return
+}
+
+//RevokeSessions 注销用户的session，handle为空时注销全部，返回注销的数量
+// 注销全部时同时使已签发的token失效，token不对应单个session，注销指定session时不受影响
+func RevokeSessions(ctx context.Context, userID, handle string) (count int, err error) {
+	if handle == "" {
+		if err = RevokeTokens(userID); err != nil {
+			return
+		}
+	}
+	sessions, err := UserSessions(userID)
+	if err != nil {
+		return
+	}
+	for _, se := range sessions {
+		if handle != "" && se.Handle() != handle {
+			continue
+		}
+		if err = se.DeleteSession(ctx); err != nil {
+			return
+		}
+		count++
+	}
+	return
+}
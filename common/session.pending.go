@@ -0,0 +1,92 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+// SessionPendingTTL 等待两步验证的session有效期，单位秒
+const SessionPendingTTL = 300
+
+// MfaAttempts 记录用户两步验证尝试次数的key前缀，MfaAttempts_<userid>
+const MfaAttempts = "MfaAttempts"
+
+// 两步验证的尝试次数按用户统计，重新登录获得新的等待中session不会重置
+const (
+	mfaMaxAttempts = 5   // 验证成功前允许的尝试次数，用完后锁定
+	mfaLockTime    = 900 // 从第一次尝试开始的统计时间，也是锁定时间，单位秒
+)
+
+var errSessionPending = errors.New("the pending session does not exist or has expired")
+
+//SessionPending 保存等待两步验证的session
+// 该session不记录到用户的session列表中，SessionCheck不会通过，只能用于提交验证码
+func (s *Session) SessionPending() (err error) {
+	if s.SessionID, err = s.CreateSessionID(); err != nil {
+		return
+	}
+	s.State = SessionStateMfaPending
+	return s.savePending()
+}
+
+//GetPendingSession 根据sessionID获取等待两步验证的session
+func GetPendingSession(sessionID string) (*Session, error) {
+	se := &Session{SessionID: sessionID}
+	if err := se.GetSessionUserID(); err == ErrStoreNil {
+		return nil, errSessionPending
+	} else if err != nil {
+		return nil, err
+	}
+	if se.State != SessionStateMfaPending {
+		return nil, errSessionPending
+	}
+	return se, nil
+}
+
+//MfaLocked 用户的两步验证是否已用完尝试次数
+func MfaLocked(userID string) (bool, error) {
+	value, err := Store.Get(mfaAttemptsKey(userID))
+	if err == ErrStoreNil {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	n, _ := strconv.Atoi(string(value))
+	return n >= mfaMaxAttempts, nil
+}
+
+//MfaAttempt 校验验证码之前记录一次尝试，返回剩余的次数，超过允许的次数时ok为false
+// 先原子计数再校验，并发的请求也不能超过允许的次数
+func MfaAttempt(userID string) (remaining int, ok bool, err error) {
+	n, err := Store.Incr(mfaAttemptsKey(userID), mfaLockTime)
+	if err != nil {
+		return
+	}
+	if n > mfaMaxAttempts {
+		return 0, false, nil
+	}
+	return mfaMaxAttempts - int(n), true, nil
+}
+
+//MfaReset 验证成功后清除尝试次数
+func MfaReset(userID string) error {
+	return Store.Del(mfaAttemptsKey(userID))
+}
+
+func mfaAttemptsKey(userID string) string {
+	return MfaAttempts + "_" + userID
+}
+
+//DeletePending 两步验证完成后删除等待中的session
+func (s *Session) DeletePending() error {
+	return Store.Del(s.SessionID)
+}
+
+func (s *Session) savePending() (err error) {
+	var value []byte
+	if value, err = json.Marshal(s); err != nil {
+		return
+	}
+	return Store.Set(s.SessionID, value, SessionPendingTTL)
+}
@@ -0,0 +1,310 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// setupSessionTest uses the memory store and returns a router with one authenticated route.
+func setupSessionTest(t *testing.T, config App) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	config.SessionStore = StoreMemory
+	CONFIG = &config
+	Store = newMemoryStore()
+	sessionSecret = []byte("session-test-secret")
+
+	g := gin.New()
+	g.Use(SessionCheck)
+	g.GET("/v1/test", func(c *gin.Context) {
+		c.String(http.StatusOK, CurrentSession(c).UserID)
+	})
+	return g
+}
+
+// registerTestSession registers a session of the user, created at createdAt when not zero.
+func registerTestSession(t *testing.T, userID string, createdAt int64) *Session {
+	t.Helper()
+	se := &Session{UserID: userID, UserName: "miku", Appid: "web", CreatedAt: createdAt}
+	var err error
+	if se.SessionID, err = se.CreateSessionID(); err != nil {
+		t.Fatal(err)
+	}
+	if err = se.SessionRegister(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return se
+}
+
+func doSessionRequest(g *gin.Engine, sessionID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/v1/test", nil)
+	if sessionID != "" {
+		req.Header.Set("Session", sessionID)
+	}
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, req)
+	return w
+}
+
+// assertRejected checks the response is the error of errType.
+func assertRejected(t *testing.T, w *httptest.ResponseRecorder, errType string) {
+	t.Helper()
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d, body %s", w.Code, http.StatusUnauthorized, w.Body)
+	}
+	var req Req
+	if err := json.Unmarshal(w.Body.Bytes(), &req); err != nil {
+		t.Fatal(err)
+	}
+	if req.Msg.ErrType != errType {
+		t.Fatalf("code = %s, want %s", req.Msg.ErrType, errType)
+	}
+}
+
+// setStoreExpire moves the expiry of a key of the memory store.
+func setStoreExpire(t *testing.T, key string, at time.Time) {
+	t.Helper()
+	m := Store.(*memoryStore)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	item, ok := m.items[key]
+	if !ok {
+		t.Fatalf("key %s not found", key)
+	}
+	item.expireAt = at
+}
+
+func storeExpireAt(key string) time.Time {
+	m := Store.(*memoryStore)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.items[key].expireAt
+}
+
+func TestSessionCheckAccept(t *testing.T) {
+	g := setupSessionTest(t, App{SessionExpireTime: 600})
+	se := registerTestSession(t, "1", 0)
+
+	w := doSessionRequest(g, se.SessionID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body)
+	}
+	if w.Body.String() != "1" {
+		t.Fatalf("user id = %s, want 1", w.Body)
+	}
+	if got := w.Header().Get("X-Session-Expires-In"); got != "600" {
+		t.Fatalf("X-Session-Expires-In = %s, want 600", got)
+	}
+}
+
+func TestSessionCheckReject(t *testing.T) {
+	g := setupSessionTest(t, App{SessionExpireTime: 600})
+	se := registerTestSession(t, "1", 0)
+	// 签名正确但未注册的session
+	unknown := &Session{}
+	unknownID, err := unknown.CreateSessionID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	deleted := registerTestSession(t, "2", 0)
+	if err = deleted.DeleteSession(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		sessionID string
+		errType   string
+	}{
+		{"missing", "", ErrMissingAuthorization},
+		{"tampered", se.SessionID + "x", ErrSession},
+		{"malformed", "not-a-session-id", ErrSession},
+		{"unknown", unknownID, ErrSession},
+		{"deleted", deleted.SessionID, ErrSession},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertRejected(t, doSessionRequest(g, tt.sessionID), tt.errType)
+		})
+	}
+}
+
+func TestSessionIdleExpiry(t *testing.T) {
+	g := setupSessionTest(t, App{SessionExpireTime: 3600, SessionIdleTimeout: 300})
+	se := registerTestSession(t, "1", 0)
+
+	// 每次请求重新设置空闲超时
+	setStoreExpire(t, se.SessionID, time.Now().Add(time.Second))
+	w := doSessionRequest(g, se.SessionID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body)
+	}
+	if got := w.Header().Get("X-Session-Expires-In"); got != "300" {
+		t.Fatalf("X-Session-Expires-In = %s, want 300", got)
+	}
+	if until := time.Until(storeExpireAt(se.SessionID)); until < 290*time.Second {
+		t.Fatalf("expiry was not slid, expires in %s", until)
+	}
+
+	// 空闲超时后拒绝
+	setStoreExpire(t, se.SessionID, time.Now().Add(-time.Second))
+	assertRejected(t, doSessionRequest(g, se.SessionID), ErrSession)
+}
+
+func TestSessionMaxLifetime(t *testing.T) {
+	g := setupSessionTest(t, App{SessionExpireTime: 600, SessionMaxLifetime: 3600})
+
+	// 剩余存活时间不足空闲超时时，过期时间不超过剩余存活时间
+	se := registerTestSession(t, "1", time.Now().Unix()-3500)
+	w := doSessionRequest(g, se.SessionID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body)
+	}
+	if got := w.Header().Get("X-Session-Lifetime"); got != "100" && got != "99" {
+		t.Fatalf("X-Session-Lifetime = %s, want 100", got)
+	}
+	if got := w.Header().Get("X-Session-Expires-In"); got != "100" && got != "99" {
+		t.Fatalf("X-Session-Expires-In = %s, want 100", got)
+	}
+
+	// 超过最长存活时间的session被拒绝并删除
+	expired := registerTestSession(t, "2", time.Now().Unix()-3601)
+	assertRejected(t, doSessionRequest(g, expired.SessionID), ErrSession)
+	if ok, _ := Store.Exists(expired.SessionID); ok {
+		t.Fatal("the expired session was not deleted")
+	}
+}
+
+func TestRefreshSession(t *testing.T) {
+	g := setupSessionTest(t, App{SessionExpireTime: 600})
+	se := registerTestSession(t, "1", 0)
+	se.Lang = "ja"
+	se.State = SessionStatePasswordExpired
+	if err := se.SessionRegister(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := se.RefreshSession(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 语言偏好及状态保留在新的session中
+	refreshed := &Session{SessionID: res.SessionID}
+	if err = refreshed.GetSession(); err != nil {
+		t.Fatal(err)
+	}
+	if refreshed.Lang != "ja" || refreshed.State != SessionStatePasswordExpired || refreshed.CreatedAt != se.CreatedAt {
+		t.Fatalf("refreshed session = %+v", refreshed)
+	}
+	assertRejected(t, doSessionRequest(g, se.SessionID), ErrSession)
+}
+
+func TestTokenRevoked(t *testing.T) {
+	g := setupSessionTest(t, App{SessionExpireTime: 600, Auth: AuthConfig{Mode: AuthModeBoth}})
+	jwtSigningMethod = jwt.SigningMethodHS256
+	jwtSignKey = []byte("token-test-secret")
+	jwtVerifyKey = jwtSignKey
+	token, err := (&Session{UserID: "1", UserName: "miku", Appid: "web"}).CreateToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v1/test", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := request(); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body)
+	}
+	// 注销全部session后，此前签发的token失效
+	if _, err = RevokeSessions(context.Background(), "1", ""); err != nil {
+		t.Fatal(err)
+	}
+	assertRejected(t, request(), ErrTokenInvalid)
+}
+
+func TestCreateTokenApiKey(t *testing.T) {
+	setupSessionTest(t, App{Auth: AuthConfig{Mode: AuthModeJwt}})
+	jwtSigningMethod = jwt.SigningMethodHS256
+	jwtSignKey = []byte("token-test-secret")
+	// 通过API Key认证的请求不签发token
+	if token, err := (&Session{UserID: "1", Appid: "apikey", ApiKey: true}).CreateToken(); err == nil || token != "" {
+		t.Fatalf("CreateToken = %q, %v, want an error", token, err)
+	}
+}
+
+func TestMfaAttempts(t *testing.T) {
+	setupSessionTest(t, App{SessionExpireTime: 600})
+
+	// 并发的尝试同样计数
+	var wg sync.WaitGroup
+	for i := 0; i < mfaMaxAttempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, ok, err := MfaAttempt("1"); err != nil || !ok {
+				t.Errorf("MfaAttempt = %v, %v", ok, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if locked, _ := MfaLocked("1"); !locked {
+		t.Fatal("the second factor is not locked after the allowed attempts")
+	}
+	if _, ok, _ := MfaAttempt("1"); ok {
+		t.Fatal("MfaAttempt allowed an attempt beyond the limit")
+	}
+	if locked, _ := MfaLocked("2"); locked {
+		t.Fatal("the attempts of another user were counted")
+	}
+
+	if err := MfaReset("1"); err != nil {
+		t.Fatal(err)
+	}
+	if remaining, ok, _ := MfaAttempt("1"); !ok || remaining != mfaMaxAttempts-1 {
+		t.Fatalf("MfaAttempt after reset = %d, %v", remaining, ok)
+	}
+}
+
+// TestMemoryStorePrune checks the memory store drops the records of sessions expired without logout.
+func TestMemoryStorePrune(t *testing.T) {
+	setupSessionTest(t, App{SessionExpireTime: 60, SessionPolicy: SessionPolicy{Mode: SessionPolicyUnlimited}})
+	expired := registerTestSession(t, "u1", 0)
+	alive := registerTestSession(t, "u1", 0)
+	gone := registerTestSession(t, "u2", 0)
+	_ = Store.HSet("other", "field", "value")
+
+	now := time.Now()
+	setStoreExpire(t, expired.SessionID, now.Add(-time.Second))
+	setStoreExpire(t, gone.SessionID, now.Add(-time.Second))
+	m := Store.(*memoryStore)
+	m.prune(now)
+
+	if _, err := Store.HGet(expired.indexKey(), expired.SessionID); err != ErrStoreNil {
+		t.Fatalf("the expired session is still recorded: %v", err)
+	}
+	if _, err := Store.HGet(alive.indexKey(), alive.SessionID); err != nil {
+		t.Fatalf("the live session was removed: %v", err)
+	}
+	m.mu.Lock()
+	_, ok := m.hashes[gone.indexKey()]
+	m.mu.Unlock()
+	if ok {
+		t.Fatal("the empty session hash was not removed")
+	}
+	// 其他哈希表的字段不是session_id，不清理
+	if _, err := Store.HGet("other", "field"); err != nil {
+		t.Fatalf("a hash of another use was pruned: %v", err)
+	}
+}
@@ -1,7 +1,6 @@
 package common
 
 import (
-	"fmt"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 	"log"
@@ -36,11 +35,17 @@ type ControlServer map[string]string
 
 //WindowsAdConfig 域账号登录配置
 type WindowsAdConfig struct {
-	Status   bool   `yaml:"Status"`
-	Server   string `yaml:"Server"`
-	Port     int    `yaml:"Port"`
-	BaseOn   string `yaml:"BaseOn"`
-	Security bool   `yaml:"Security"`
+	Status         bool              `yaml:"Status"`
+	Server         string            `yaml:"Server"`
+	Port           int               `yaml:"Port"`
+	BaseOn         string            `yaml:"BaseOn"`
+	Security       bool              `yaml:"Security"`
+	BindDN         string            `yaml:"BindDN"`         // 查询用户使用的服务账号，为空时匿名查询
+	BindPassword   string            `yaml:"BindPassword"`   // 服务账号密码
+	UserFilter     string            `yaml:"UserFilter"`     // 查询用户的条件，%s 为登录用户名
+	GroupAttribute string            `yaml:"GroupAttribute"` // 用户所属组的属性
+	GroupRoles     map[string]string `yaml:"GroupRoles"`     // 目录组（DN或CN）对应的角色名称
+	DefaultRoles   []string          `yaml:"DefaultRoles"`   // 所有域账号都拥有的角色
 }
 
 //RedisServer redis配置
@@ -49,13 +54,66 @@ type RedisServer struct {
 	Password string `yaml:"Password"`
 }
 
+//LogConfig 日志文件配置
+type LogConfig struct {
+	Dir        string `yaml:"Dir"`        // 日志目录，为空时只输出到终端
+	Stdout     bool   `yaml:"Stdout"`     // 写入文件的同时输出到终端
+	Rotate     string `yaml:"Rotate"`     // size 或 daily
+	MaxSize    int    `yaml:"MaxSize"`    // 单个文件的最大大小，单位MB，为0时不按大小切割
+	MaxAge     int    `yaml:"MaxAge"`     // 切割后的文件保留天数，为0时不按时间清理
+	MaxBackups int    `yaml:"MaxBackups"` // 切割后的文件保留数量，为0时不按数量清理
+	Compress   bool   `yaml:"Compress"`   // 使用gzip压缩切割后的文件
+}
+
+//SessionPolicy 同一用户的session数量策略
+type SessionPolicy struct {
+	Mode        string `yaml:"Mode"`        // single, limit, unlimited
+	MaxSessions int    `yaml:"MaxSessions"` // limit 模式下最多保留的session数量
+}
+
+//PasswordPolicy 密码策略
+type PasswordPolicy struct {
+	Algorithm     string `yaml:"Algorithm"`     // 哈希算法 bcrypt 或 argon2id
+	MinLength     int    `yaml:"MinLength"`     // 最小长度
+	RequireUpper  bool   `yaml:"RequireUpper"`  // 必须包含大写字母
+	RequireLower  bool   `yaml:"RequireLower"`  // 必须包含小写字母
+	RequireDigit  bool   `yaml:"RequireDigit"`  // 必须包含数字
+	RequireSymbol bool   `yaml:"RequireSymbol"` // 必须包含特殊字符
+	History       int    `yaml:"History"`       // 不能与最近N次使用过的密码相同
+	MaxAge        int    `yaml:"MaxAge"`        // 密码有效天数，为0时不过期
+}
+
+//JwtConfig JWT签发配置
+type JwtConfig struct {
+	Algorithm  string `yaml:"Algorithm"`  // HS256 或 RS256
+	PrivateKey string `yaml:"PrivateKey"` // RS256 私钥文件路径
+	PublicKey  string `yaml:"PublicKey"`  // RS256 公钥文件路径，为空时由私钥导出
+	ExpireTime int    `yaml:"ExpireTime"` // token过期时间，单位秒
+}
+
+//AuthConfig 认证配置
+type AuthConfig struct {
+	Mode string    `yaml:"Mode"` // session, jwt, both
+	Jwt  JwtConfig `yaml:"Jwt"`
+}
+
 // 应用初始信息
 type App struct {
-	Name              string      `yaml:"Name"`
-	Version           string      `yaml:"Version"`
-	DB                DbConfig    `yaml:"DB"`
-	Redis             RedisServer `yaml:"Redis"`
-	SessionExpireTime int         `yaml:"SessionExpireTime"`
+	Name               string          `yaml:"Name"`
+	Version            string          `yaml:"Version"`
+	Log                LogConfig       `yaml:"Log"`
+	DB                 DbConfig        `yaml:"DB"`
+	Redis              RedisServer     `yaml:"Redis"`
+	SessionStore       string          `yaml:"SessionStore"` // redis 或 memory
+	Auth               AuthConfig      `yaml:"Auth"`
+	Password           PasswordPolicy  `yaml:"Password"`
+	WindowsAd          WindowsAdConfig `yaml:"WindowsAd"`
+	SessionExpireTime  int             `yaml:"SessionExpireTime"`
+	SessionIdleTimeout int             `yaml:"SessionIdleTimeout"` // 空闲超时，每次请求重置，为0时使用SessionExpireTime
+	SessionMaxLifetime int             `yaml:"SessionMaxLifetime"` // 登录后的最长存活时间，为0时不限制
+	SessionPolicy      SessionPolicy   `yaml:"SessionPolicy"`
+	ErrnoReload        int             `yaml:"ErrnoReload"`      // 定时重新加载错误信息的间隔，单位秒，为0时不定时加载
+	ErrnoProblemType   string          `yaml:"ErrnoProblemType"` // problem+json中type的前缀，后接错误类型
 }
 
 //func DBParse() {
@@ -92,7 +150,7 @@ func ConfigParse() {
 	CONFIG = &res
 	ConfigDe := res
 	ConfigDe.DB.Password = "******"
+	ConfigDe.WindowsAd.BindPassword = "******"
 	LogDebugf("load config from file", logrus.Fields{"CONFIG": ConfigDe})
-	RedisInit()
-	LogInfo(fmt.Sprintf("load redis config success, redis address is %s", res.Redis))
+	SessionStoreInit()
 }
@@ -0,0 +1,50 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 会话存储类型
+const (
+	StoreRedis  = "redis"  // 保存至Redis，多节点部署时使用
+	StoreMemory = "memory" // 保存在进程内存中，单节点部署或无Redis环境使用
+)
+
+//ErrStoreNil 查询的key或field不存在
+var ErrStoreNil = errors.New("store: nil")
+
+//Store 当前使用的会话存储
+var Store SessionStore
+
+//SessionStore 会话存储接口，操作语义与Redis一致，ttl单位为秒，为0时不过期
+type SessionStore interface {
+	Set(key string, value []byte, ttl int) error
+	Get(key string) ([]byte, error)
+	Exists(key string) (bool, error)
+	Expire(key string, ttl int) error
+	Incr(key string, ttl int) (int64, error) // 原子自增，key不存在时从0开始并设置过期时间
+	Del(key string) error
+	HSet(hash, field, value string) error
+	HGet(hash, field string) (string, error)
+	HDel(hash, field string) error
+	HGetAll(hash string) (map[string]string, error)
+}
+
+//SessionStoreInit 根据配置初始化会话存储，默认使用Redis
+func SessionStoreInit() {
+	switch strings.ToLower(CONFIG.SessionStore) {
+	case "", StoreRedis:
+		RedisInit()
+		Store = &redisStore{}
+		LogInfo(fmt.Sprintf("load redis config success, redis address is %s", CONFIG.Redis))
+	case StoreMemory:
+		Store = newMemoryStore()
+		LogInfo("use memory session store, sessions will not be shared between instances")
+	default:
+		LogFatalf("Unsupported session store.", logrus.Fields{"store": CONFIG.SessionStore})
+	}
+}
@@ -0,0 +1,203 @@
+package common
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// memoryCleanInterval 清理过期key的间隔
+const memoryCleanInterval = time.Minute
+
+type memoryItem struct {
+	value    []byte
+	expireAt time.Time // 零值表示不过期
+}
+
+func (i *memoryItem) expired(now time.Time) bool {
+	return !i.expireAt.IsZero() && now.After(i.expireAt)
+}
+
+// memoryStore 进程内的会话存储，带过期时间的map
+type memoryStore struct {
+	mu     sync.Mutex
+	items  map[string]*memoryItem
+	hashes map[string]map[string]string
+}
+
+func newMemoryStore() *memoryStore {
+	m := &memoryStore{
+		items:  make(map[string]*memoryItem),
+		hashes: make(map[string]map[string]string),
+	}
+	go m.clean()
+	return m
+}
+
+// clean 定期删除过期的key
+func (m *memoryStore) clean() {
+	ticker := time.NewTicker(memoryCleanInterval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		m.prune(now)
+	}
+}
+
+// prune 删除过期的key，以及用户session哈希表中已过期session的记录
+// 未退出登录而过期的session不会调用HDel，不清理时哈希表会一直保留
+func (m *memoryStore) prune(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key, item := range m.items {
+		if item.expired(now) {
+			delete(m.items, key)
+		}
+	}
+	for hash, fields := range m.hashes {
+		if !strings.HasPrefix(hash, SessionHash+"_") {
+			continue
+		}
+		for sessionID := range fields {
+			if _, ok := m.items[sessionID]; !ok {
+				delete(fields, sessionID)
+			}
+		}
+		if len(fields) == 0 {
+			delete(m.hashes, hash)
+		}
+	}
+}
+
+// get 获取未过期的key，调用方需持有锁
+func (m *memoryStore) get(key string) *memoryItem {
+	item, ok := m.items[key]
+	if !ok {
+		return nil
+	}
+	if item.expired(time.Now()) {
+		delete(m.items, key)
+		return nil
+	}
+	return item
+}
+
+func expireAt(ttl int) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(time.Duration(ttl) * time.Second)
+}
+
+func (m *memoryStore) Set(key string, value []byte, ttl int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.items[key] = &memoryItem{
+		value:    append([]byte(nil), value...),
+		expireAt: expireAt(ttl),
+	}
+	return nil
+}
+
+func (m *memoryStore) Get(key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	item := m.get(key)
+	if item == nil {
+		return nil, ErrStoreNil
+	}
+	return append([]byte(nil), item.value...), nil
+}
+
+func (m *memoryStore) Exists(key string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.get(key) != nil, nil
+}
+
+func (m *memoryStore) Expire(key string, ttl int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if item := m.get(key); item != nil {
+		// 与Redis一致，非正数的ttl会立即删除key
+		if ttl <= 0 {
+			delete(m.items, key)
+			return nil
+		}
+		item.expireAt = expireAt(ttl)
+	}
+	return nil
+}
+
+func (m *memoryStore) Incr(key string, ttl int) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var n int64
+	item := m.get(key)
+	if item == nil {
+		item = &memoryItem{expireAt: expireAt(ttl)}
+		m.items[key] = item
+	} else {
+		var err error
+		// 与Redis一致，非整数的值不能自增
+		if n, err = strconv.ParseInt(string(item.value), 10, 64); err != nil {
+			return 0, errors.New("store: value is not an integer")
+		}
+	}
+	n++
+	item.value = []byte(strconv.FormatInt(n, 10))
+	return n, nil
+}
+
+func (m *memoryStore) Del(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.items, key)
+	delete(m.hashes, key)
+	return nil
+}
+
+func (m *memoryStore) HSet(hash, field, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	fields, ok := m.hashes[hash]
+	if !ok {
+		fields = make(map[string]string)
+		m.hashes[hash] = fields
+	}
+	fields[field] = value
+	return nil
+}
+
+func (m *memoryStore) HGet(hash, field string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	value, ok := m.hashes[hash][field]
+	if !ok {
+		return "", ErrStoreNil
+	}
+	return value, nil
+}
+
+func (m *memoryStore) HDel(hash, field string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if fields, ok := m.hashes[hash]; ok {
+		delete(fields, field)
+		if len(fields) == 0 {
+			delete(m.hashes, hash)
+		}
+	}
+	return nil
+}
+
+func (m *memoryStore) HGetAll(hash string) (map[string]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	res := make(map[string]string, len(m.hashes[hash]))
+	for field, value := range m.hashes[hash] {
+		res[field] = value
+	}
+	return res, nil
+}
@@ -0,0 +1,82 @@
+package common
+
+import (
+	"github.com/gomodule/redigo/redis"
+)
+
+// incrScript 自增并在首次创建时设置过期时间，避免INCR与EXPIRE之间中断导致key不过期
+var incrScript = redis.NewScript(1, `
+local n = redis.call("INCR", KEYS[1])
+if n == 1 and tonumber(ARGV[1]) > 0 then
+	redis.call("EXPIRE", KEYS[1], ARGV[1])
+end
+return n`)
+
+// redisStore 使用Redis保存会话，连接来自全局的Pool
+type redisStore struct{}
+
+func (r *redisStore) do(cmd string, args ...interface{}) (interface{}, error) {
+	conn := Pool.Get()
+	defer conn.Close()
+	return conn.Do(cmd, args...)
+}
+
+func (r *redisStore) Set(key string, value []byte, ttl int) (err error) {
+	if ttl > 0 {
+		_, err = r.do("SET", key, value, "EX", ttl)
+	} else {
+		_, err = r.do("SET", key, value)
+	}
+	return
+}
+
+func (r *redisStore) Get(key string) ([]byte, error) {
+	return redisResult(redis.Bytes(r.do("GET", key)))
+}
+
+func (r *redisStore) Exists(key string) (bool, error) {
+	return redis.Bool(r.do("EXISTS", key))
+}
+
+func (r *redisStore) Expire(key string, ttl int) (err error) {
+	_, err = r.do("EXPIRE", key, ttl)
+	return
+}
+
+func (r *redisStore) Incr(key string, ttl int) (int64, error) {
+	conn := Pool.Get()
+	defer conn.Close()
+	return redis.Int64(incrScript.Do(conn, key, ttl))
+}
+
+func (r *redisStore) Del(key string) (err error) {
+	_, err = r.do("DEL", key)
+	return
+}
+
+func (r *redisStore) HSet(hash, field, value string) (err error) {
+	_, err = r.do("HSET", hash, field, value)
+	return
+}
+
+func (r *redisStore) HGet(hash, field string) (string, error) {
+	value, err := redisResult(redis.Bytes(r.do("HGET", hash, field)))
+	return string(value), err
+}
+
+func (r *redisStore) HDel(hash, field string) (err error) {
+	_, err = r.do("HDEL", hash, field)
+	return
+}
+
+func (r *redisStore) HGetAll(hash string) (map[string]string, error) {
+	return redis.StringMap(r.do("HGETALL", hash))
+}
+
+// redisResult 将redis.ErrNil转换为ErrStoreNil
+func redisResult(value []byte, err error) ([]byte, error) {
+	if err == redis.ErrNil {
+		return nil, ErrStoreNil
+	}
+	return value, err
+}
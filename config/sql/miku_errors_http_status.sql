@@ -0,0 +1,10 @@
+-- 为错误信息表增加HTTP状态码，未设置（为0）的错误类型返回500
+ALTER TABLE miku_errors ADD COLUMN http_status INT NOT NULL DEFAULT 0 AFTER error_code;
+
+UPDATE miku_errors SET http_status = 400 WHERE err_type IN ('ErrBind', 'ErrValidation', 'ErrValidate', 'ErrDecode', 'ErrBuildParams', 'ErrPasswordRule');
+UPDATE miku_errors SET http_status = 401 WHERE err_type IN ('ErrMissingAuthorization', 'ErrTokenInvalid', 'ErrTokenParse', 'ErrSession', 'ErrUserIncorrect', 'ErrGoogleVerify', 'ErrWindowsADFailed');
+UPDATE miku_errors SET http_status = 403 WHERE err_type IN ('ErrIamForbidden', 'ErrPermission', 'ErrUserStatus', 'ErrPasswordExpired', 'ErrUserAccess', 'ErrIpAccess', 'ErrLoginIpAccess', 'ErrLicenseExpired', 'ErrLicenseAuthorization');
+UPDATE miku_errors SET http_status = 404 WHERE err_type IN ('ErrRecordNotFound', 'ErrInstanceNotFound', 'ErrLogMonitorNotFound', 'ErrFileIsNotExist');
+UPDATE miku_errors SET http_status = 409 WHERE err_type IN ('ErrDuplicate', 'ErrTaskIsStillRunning');
+UPDATE miku_errors SET http_status = 429 WHERE err_type IN ('ErrRateLimit');
+UPDATE miku_errors SET http_status = 502 WHERE err_type IN ('ErrDBProxy', 'ErrMonitorProxy', 'ErrAnthenaProxy', 'ErrZeusProxy', 'ErrSSHProxy', 'ErrWindowsAdError', 'ErrConnectFailed');
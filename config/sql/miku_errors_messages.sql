@@ -0,0 +1,2 @@
+-- 为错误信息表增加其他语言的错误信息，格式为 {"ja": "...", "zh-tw": "..."}
+ALTER TABLE miku_errors ADD COLUMN messages TEXT NULL AFTER message_zh;
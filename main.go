@@ -29,6 +29,12 @@ func main() {
 			Usage:   "the log level mode.",
 			Value:   "DEBUG",
 		},
+		&cli.StringFlag{
+			EnvVars: []string{"MICROSERVICE_LOG_FORMAT"},
+			Name:    "log-format",
+			Usage:   "the log output format, text or json.",
+			Value:   "text",
+		},
 		&cli.StringFlag{
 			EnvVars: []string{"MICROSERVICE_ADDR"},
 			Name:    "addr",
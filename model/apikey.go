@@ -0,0 +1,34 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+//ApiKey 服务间调用使用的API Key，只保存key的哈希
+type ApiKey struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"index" json:"user_id"`         // 所属用户，使用key的请求以该用户身份访问
+	Name       string     `gorm:"size:64" json:"name"`          // 名称
+	Prefix     string     `gorm:"size:16" json:"prefix"`        // key的前几位，便于识别
+	KeyHash    string     `gorm:"size:64;uniqueIndex" json:"-"` // key的sha256
+	Scopes     string     `gorm:"size:1024" json:"-"`           // 授权范围，逗号分隔的权限
+	ExpiresAt  *time.Time `json:"expires_at"`                   // 过期时间，为空时不过期
+	LastUsedAt *time.Time `json:"last_used_at"`                 // 最后使用时间
+	RevokedAt  *time.Time `gorm:"index" json:"revoked_at"`      // 吊销时间
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+//ScopeList 授权范围列表
+func (k *ApiKey) ScopeList() []string {
+	if k.Scopes == "" {
+		return []string{}
+	}
+	return strings.Split(k.Scopes, ",")
+}
+
+//Expired 是否已过期
+func (k *ApiKey) Expired(now time.Time) bool {
+	return k.ExpiresAt != nil && now.After(*k.ExpiresAt)
+}
@@ -1 +1,13 @@
 package model
+
+// Models 返回需要自动迁移的数据表模型，由 common.DatabaseConnect 在启动时迁移
+func Models() []interface{} {
+	return []interface{}{
+		&User{},
+		&Role{},
+		&Permission{},
+		&ApiKey{},
+		&RecoveryCode{},
+		&PasswordHistory{},
+	}
+}
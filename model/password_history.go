@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+//PasswordHistory 用户使用过的密码哈希，用于禁止重复使用最近的密码
+type PasswordHistory struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"index" json:"user_id"`
+	Hash      string    `gorm:"size:255" json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
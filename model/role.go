@@ -0,0 +1,16 @@
+package model
+
+//Role 角色，用户通过角色获得权限
+type Role struct {
+	ID          uint         `gorm:"primaryKey" json:"id"`
+	Name        string       `gorm:"size:64;uniqueIndex" json:"name"`
+	Description string       `gorm:"size:255" json:"description"`
+	Permissions []Permission `gorm:"many2many:role_permission" json:"permissions"`
+}
+
+//Permission 权限，Code与路由声明的权限一致，如 session:admin，"*" 表示拥有全部权限
+type Permission struct {
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	Code        string `gorm:"size:64;uniqueIndex" json:"code"`
+	Description string `gorm:"size:255" json:"description"`
+}
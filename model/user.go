@@ -0,0 +1,57 @@
+package model
+
+import "time"
+
+// 用户来源
+const (
+	UserSourceLocal = ""     // 本地账号，密码保存在users表
+	UserSourceLdap  = "ldap" // 域账号，首次登录时自动创建，密码由目录服务校验
+)
+
+//User 登录用户
+type User struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	UserName     string     `gorm:"size:64;uniqueIndex" json:"user_name"` // 登录用户名
+	Password     string     `gorm:"size:255" json:"-"`                    // 密码哈希
+	PasswordAt   *time.Time `json:"password_at"`                          // 密码最后修改时间，为空时使用创建时间
+	Lock         bool       `json:"lock"`                                 // 锁定
+	Disabled     bool       `json:"disabled"`                             // 禁用的用户无法登录
+	Source       string     `gorm:"size:16" json:"source"`                // 用户来源，为空时为本地账号
+	TotpSecret   string     `gorm:"size:64" json:"-"`                     // 两步验证的TOTP密钥，绑定中或已启用
+	TotpEnabled  bool       `json:"totp_enabled"`                         // 是否已启用两步验证
+	TotpLastStep int64      `json:"-"`                                    // 最后使用的时间步，防止验证码重复使用
+	Lang         string     `gorm:"size:16" json:"lang"`                  // 错误信息的语言偏好，为空时使用Accept-Language
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	Roles        []Role     `gorm:"many2many:user_role" json:"roles"`
+}
+
+//PasswordChangedAt 密码最后修改时间
+func (u *User) PasswordChangedAt() time.Time {
+	if u.PasswordAt != nil {
+		return *u.PasswordAt
+	}
+	return u.CreatedAt
+}
+
+//RoleNames 用户的角色名称，需预加载Roles
+func (u *User) RoleNames() (res []string) {
+	for _, r := range u.Roles {
+		res = append(res, r.Name)
+	}
+	return
+}
+
+//PermissionCodes 用户所有角色的权限去重后的列表，需预加载Roles.Permissions
+func (u *User) PermissionCodes() (res []string) {
+	seen := make(map[string]bool)
+	for _, r := range u.Roles {
+		for _, p := range r.Permissions {
+			if !seen[p.Code] {
+				seen[p.Code] = true
+				res = append(res, p.Code)
+			}
+		}
+	}
+	return
+}
@@ -12,11 +12,15 @@ import (
 func Load(g *gin.Engine, mw ...gin.HandlerFunc) *gin.Engine {
 	// Middlewares.
 	g.Use(gin.Recovery())
+	// The request logger is attached first, so every middleware logs with the request id.
+	g.Use(common.RequestId)
 	//g.Use(common.NoCache)
 	g.Use(common.Options)
 	g.Use(common.Secure)
 	g.Use(mw...)
-	g.Use(common.RequestId)
+	g.Use(common.RenderErrors)
+	// 在handler执行前协商响应格式
+	g.Use(common.Negotiate)
 	g.Use(common.SessionCheck)
 
 	// 404 Handler.
@@ -24,12 +28,63 @@ func Load(g *gin.Engine, mw ...gin.HandlerFunc) *gin.Engine {
 		c.String(http.StatusNotFound, "The incorrect API route.")
 	})
 
+	r := newRoutes(&g.RouterGroup)
+
 	// health check
-	g.GET("/health", func(context *gin.Context) {
+	r.public(http.MethodGet, "/health", func(context *gin.Context) {
 		common.LogInfo("check interfaces success")
 	})
 
-	g.GET("/v1/bili/video/info", api.GetVideoInfo)
+	r.public(http.MethodGet, "/v1/bili/video/info", common.ETag, api.GetVideoInfo)
+
+	// 登录认证
+	r.public(http.MethodPost, "/v1/auth/login", api.Login)
+	r.public(http.MethodPost, "/v1/auth/logout", api.Logout)
+	r.auth(http.MethodPost, "/v1/auth/refresh", "", api.RefreshSession)
+	r.auth(http.MethodPut, "/v1/auth/lang", "", api.UpdateLanguage)
+
+	// 修改密码，密码过期的session也可访问
+	r.restricted(http.MethodPut, "/v1/auth/password", api.ChangePassword)
+
+	// 两步验证
+	r.public(http.MethodPost, "/v1/auth/totp/verify", api.VerifyTotp)
+	r.auth(http.MethodPost, "/v1/auth/totp/enroll", "", api.EnrollTotp)
+	r.auth(http.MethodPost, "/v1/auth/totp/activate", "", api.ActivateTotp)
+	r.auth(http.MethodDelete, "/v1/auth/totp", "", api.DisableTotp)
+
+	// 多设备session管理
+	r.auth(http.MethodGet, "/v1/auth/sessions", "", api.ListSessions)
+	r.auth(http.MethodDelete, "/v1/auth/sessions", "", api.RevokeAllSessions)
+	r.auth(http.MethodDelete, "/v1/auth/sessions/:handle", "", api.RevokeSession)
+
+	// 服务间调用的API Key
+	r.auth(http.MethodGet, "/v1/apikeys", common.PermApiKeyManage, common.Handle(api.ListApiKeys))
+	r.auth(http.MethodPost, "/v1/apikeys", common.PermApiKeyManage, api.CreateApiKey)
+	r.auth(http.MethodPost, "/v1/apikeys/:id/rotate", common.PermApiKeyManage, api.RotateApiKey)
+	r.auth(http.MethodDelete, "/v1/apikeys/:id", common.PermApiKeyManage, api.RevokeApiKey)
+
+	// 管理员接口
+	admin := r.group("/v1/admin")
+	{
+		admin.auth(http.MethodGet, "/users/:userid/sessions", common.PermSessionAdmin, api.ListUserSessions)
+		admin.auth(http.MethodDelete, "/users/:userid/sessions", common.PermSessionAdmin, api.RevokeUserSessions)
+		admin.auth(http.MethodDelete, "/users/:userid/sessions/:handle", common.PermSessionAdmin, api.RevokeUserSession)
+
+		// 错误信息管理
+		admin.auth(http.MethodGet, "/errors", common.PermErrnoManage, common.Handle(api.ListErrors))
+		admin.auth(http.MethodPost, "/errors", common.PermErrnoManage, common.Handle(api.CreateError))
+		admin.auth(http.MethodPut, "/errors/:type", common.PermErrnoManage, common.Handle(api.UpdateError))
+		admin.auth(http.MethodPost, "/errors/reload", common.PermErrnoManage, common.Handle(api.ReloadErrors))
+
+		// 运行时的日志级别
+		admin.auth(http.MethodGet, "/log/level", common.PermLogManage, common.Handle(api.GetLogLevel))
+		admin.auth(http.MethodPut, "/log/level", common.PermLogManage, common.Handle(api.SetLogLevel))
+		admin.auth(http.MethodPut, "/log/routes", common.PermLogManage, common.Handle(api.SetRouteLogLevel))
+		admin.auth(http.MethodDelete, "/log/routes", common.PermLogManage, common.Handle(api.DeleteRouteLogLevel))
+		admin.auth(http.MethodPost, "/log/reopen", common.PermLogManage, common.Handle(api.ReopenLogFiles))
+	}
+
+	common.LogRoutes(g.Routes())
 
 	return g
 }
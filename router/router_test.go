@@ -0,0 +1,67 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-api/common"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupRouter 加载全部路由，数据库只生成SQL，查询返回零值的记录
+func setupRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	common.CONFIG = &common.App{Auth: common.AuthConfig{Mode: common.AuthModeBoth}}
+	db, err := gorm.Open(mysql.New(mysql.Config{SkipInitializeWithVersion: true}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+		Logger:               logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Load(gin.New(), func(c *gin.Context) {
+		c.Set("DB", db)
+	})
+}
+
+func TestApiKeyAccountRoutes(t *testing.T) {
+	g := setupRouter(t)
+	tests := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodPost, "/v1/auth/refresh"},
+		{http.MethodPut, "/v1/auth/lang"},
+		{http.MethodPut, "/v1/auth/password"},
+		{http.MethodPost, "/v1/auth/totp/enroll"},
+		{http.MethodPost, "/v1/auth/totp/activate"},
+		{http.MethodDelete, "/v1/auth/totp"},
+		{http.MethodGet, "/v1/auth/sessions"},
+		{http.MethodDelete, "/v1/auth/sessions"},
+		{http.MethodDelete, "/v1/auth/sessions/abc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader("{}"))
+			req.Header.Set("X-Api-Key", common.ApiKeyPrefix+"test")
+			w := httptest.NewRecorder()
+			g.ServeHTTP(w, req)
+			var res common.Req
+			if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+				t.Fatalf("body %s: %v", w.Body, err)
+			}
+			if w.Code != http.StatusForbidden || res.Msg.ErrType != common.ErrPermission {
+				t.Fatalf("status = %d, body %s, want %s", w.Code, w.Body, common.ErrPermission)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package router
+
+import (
+	"go-api/common"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// routes wraps a router group so that every route is registered together with its access rule.
+type routes struct {
+	rg *gin.RouterGroup
+}
+
+func newRoutes(group *gin.RouterGroup) *routes {
+	return &routes{rg: group}
+}
+
+// group creates a sub group with the given path prefix.
+func (r *routes) group(relativePath string, handlers ...gin.HandlerFunc) *routes {
+	return newRoutes(r.rg.Group(relativePath, handlers...))
+}
+
+// public registers a route that can be called without authentication.
+func (r *routes) public(method, relativePath string, handlers ...gin.HandlerFunc) {
+	r.handle(common.RouteAccess{Method: method, Public: true}, relativePath, handlers)
+}
+
+// auth registers a route that requires an authenticated user,
+// and the permission as well when perm is not empty.
+func (r *routes) auth(method, relativePath, perm string, handlers ...gin.HandlerFunc) {
+	if perm != "" {
+		handlers = append([]gin.HandlerFunc{common.RequirePermission(perm)}, handlers...)
+	}
+	r.handle(common.RouteAccess{Method: method, Permission: perm}, relativePath, handlers)
+}
+
+// restricted registers a route that requires an authenticated user and can also be
+// called by a restricted session whose password has expired, such as changing the password.
+func (r *routes) restricted(method, relativePath string, handlers ...gin.HandlerFunc) {
+	r.handle(common.RouteAccess{Method: method, AllowPasswordExpired: true}, relativePath, handlers)
+}
+
+func (r *routes) handle(access common.RouteAccess, relativePath string, handlers []gin.HandlerFunc) {
+	r.rg.Handle(access.Method, relativePath, handlers...)
+	access.Path = joinPath(r.rg.BasePath(), relativePath)
+	common.RegisterRoute(access)
+}
+
+// joinPath joins the paths the same way gin does, keeping the trailing slash.
+func joinPath(basePath, relativePath string) string {
+	if relativePath == "" {
+		return basePath
+	}
+	res := path.Join(basePath, relativePath)
+	if strings.HasSuffix(relativePath, "/") && !strings.HasSuffix(res, "/") {
+		return res + "/"
+	}
+	return res
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/urfave/cli/v2"
 	"go-api/common"
+	"go-api/model"
 	"go-api/router"
 	"net/http"
 	"strings"
@@ -30,6 +31,11 @@ func server(c *cli.Context, started chan bool) error {
 	// Load config file and save in global var CFG
 	//common.DBParse()
 	common.ConfigParse()
+	// Write the logs to the files of the config.
+	common.LogFileInit()
+	// Initialize the session id and json web token signing keys.
+	common.SessionInit(c)
+	common.JwtInit(c)
 	// Load pprof
 	ginpprof.Wrapper(g)
 
@@ -39,10 +45,12 @@ func server(c *cli.Context, started chan bool) error {
 	router.Load(
 		g,
 		common.MiddlewareConfig(c),
-		common.DatabaseConnect("miku"),
+		common.DatabaseConnect("miku", model.Models()...),
 		common.RateLimit(),
 		common.MiddleLogging(),
 	)
+	// Keep the error messages in sync with the database and the other instances.
+	common.ErrnoWatch()
 	go func() {
 		if err := pingServer(c); err != nil {
 			common.LogFatal("The router has no response, or it might took too long to start up.")
@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// 密码哈希算法
+const (
+	PasswordBcrypt   = "bcrypt"
+	PasswordArgon2id = "argon2id"
+)
+
+// argon2id 参数，参考 RFC 9106 的推荐值
+const (
+	argon2Memory  = 64 * 1024
+	argon2Time    = 3
+	argon2Threads = 2
+	argon2KeyLen  = 32
+	argon2SaltLen = 16
+)
+
+var errArgon2Hash = errors.New("the argon2id hash is malformed")
+
+// PasswordHash 使用bcrypt生成密码哈希，传入明文密码
+func PasswordHash(password string) (hash string, err error) {
+	return PasswordHashWith(PasswordBcrypt, password)
+}
+
+// PasswordHashWith 使用指定算法生成密码哈希，支持bcrypt及argon2id
+func PasswordHashWith(algorithm, password string) (hash string, err error) {
+	switch algorithm {
+	case "", PasswordBcrypt:
+		var hashBytes []byte
+		hashBytes, err = bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		hash = string(hashBytes)
+	case PasswordArgon2id:
+		salt := make([]byte, argon2SaltLen)
+		if _, err = rand.Read(salt); err != nil {
+			return
+		}
+		key := argon2.IDKey([]byte(password), salt, argon2Time, argon2Memory, argon2Threads, argon2KeyLen)
+		hash = fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, argon2Memory, argon2Time, argon2Threads,
+			base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))
+	default:
+		err = fmt.Errorf("unsupported password algorithm %s", algorithm)
+	}
+	return
+}
+
+// PasswordCompare 校验明文密码与哈希是否一致，根据哈希的格式判断算法
+func PasswordCompare(hash, password string) bool {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		ok, err := argon2Compare(hash, password)
+		return err == nil && ok
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// argon2Compare 使用哈希中记录的参数重新计算并比较
+func argon2Compare(hash, password string) (bool, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return false, errArgon2Hash
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, errArgon2Hash
+	}
+	var memory, time uint32
+	var threads uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil {
+		return false, errArgon2Hash
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, errArgon2Hash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return false, errArgon2Hash
+	}
+	other := argon2.IDKey([]byte(password), salt, time, memory, threads, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
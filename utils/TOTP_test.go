@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+// rfc6238Secret 为 RFC 6238 附录B中SHA1使用的密钥 "12345678901234567890"
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode(t *testing.T) {
+	// RFC 6238 附录B的8位验证码取后TOTPDigits位
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+	}
+	for _, tt := range tests {
+		want := tt.want[len(tt.want)-TOTPDigits:]
+		got, err := TOTPCode(rfc6238Secret, TOTPStep(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Fatalf("TOTPCode at %d = %s, want %s", tt.unix, got, want)
+		}
+	}
+}
+
+func TestTOTPVerify(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	code, _ := TOTPCode(rfc6238Secret, TOTPStep(now)-1)
+	if step, ok := TOTPVerify(rfc6238Secret, code, now, 1); !ok || step != TOTPStep(now)-1 {
+		t.Fatalf("TOTPVerify of the previous step = %d, %v", step, ok)
+	}
+	if _, ok := TOTPVerify(rfc6238Secret, code, now, 0); ok {
+		t.Fatal("TOTPVerify accepted the previous step without skew")
+	}
+}